@@ -0,0 +1,11 @@
+package ffprobe
+
+// Illuminance returns the ambient illuminance in lux
+func (a *SideDataAmbientViewingEnv) Illuminance() float64 {
+	return a.AmbientIlluminance.Float64()
+}
+
+// LightChromaticity returns the chromaticity of the ambient light
+func (a *SideDataAmbientViewingEnv) LightChromaticity() Chromaticity {
+	return Chromaticity{X: a.AmbientLightX.Float64(), Y: a.AmbientLightY.Float64()}
+}
@@ -0,0 +1,77 @@
+package ffprobe
+
+// DownmixType is the preferred downmix type of the downmix info side data
+type DownmixType int
+
+const (
+	// DownmixTypeUnknown means no preference
+	DownmixTypeUnknown DownmixType = iota
+	// DownmixTypeLoRo is a Lo/Ro stereo downmix
+	DownmixTypeLoRo
+	// DownmixTypeLtRt is a Lt/Rt downmix, compatible with Dolby Surround decoders
+	DownmixTypeLtRt
+	// DownmixTypeDPLII is a downmix compatible with Dolby Pro Logic II decoders
+	DownmixTypeDPLII
+)
+
+var downmixTypeNames = []string{"unknown", "lo_ro", "lt_rt", "dpl2"}
+
+// String returns the name of the downmix type
+func (t DownmixType) String() string {
+	if t < 0 || int(t) >= len(downmixTypeNames) {
+		return "unknown"
+	}
+	return downmixTypeNames[t]
+}
+
+// UnmarshalJSON unmarshals the downmix type from its number or name
+func (t *DownmixType) UnmarshalJSON(b []byte) error {
+	value, err := unmarshalEnum(b, downmixTypeNames)
+	*t = DownmixType(value)
+	return err
+}
+
+// AudioServiceType is the type of service of an audio stream, like commentary or a description for the visually
+// impaired
+type AudioServiceType int
+
+const (
+	// AudioServiceTypeMain is the main audio service
+	AudioServiceTypeMain AudioServiceType = iota
+	// AudioServiceTypeEffects is music and effects without dialogue
+	AudioServiceTypeEffects
+	// AudioServiceTypeVisuallyImpaired is an audio description for the visually impaired
+	AudioServiceTypeVisuallyImpaired
+	// AudioServiceTypeHearingImpaired is audio for the hearing impaired, with enhanced dialogue
+	AudioServiceTypeHearingImpaired
+	// AudioServiceTypeDialogue is dialogue only
+	AudioServiceTypeDialogue
+	// AudioServiceTypeCommentary is a commentary
+	AudioServiceTypeCommentary
+	// AudioServiceTypeEmergency is an emergency announcement
+	AudioServiceTypeEmergency
+	// AudioServiceTypeVoiceOver is a voice over
+	AudioServiceTypeVoiceOver
+	// AudioServiceTypeKaraoke is karaoke audio, without the vocals
+	AudioServiceTypeKaraoke
+)
+
+var audioServiceTypeNames = []string{
+	"main", "effects", "visually_impaired", "hearing_impaired", "dialogue", "commentary", "emergency", "voice_over",
+	"karaoke",
+}
+
+// String returns the name of the audio service type
+func (t AudioServiceType) String() string {
+	if t < 0 || int(t) >= len(audioServiceTypeNames) {
+		return "unknown"
+	}
+	return audioServiceTypeNames[t]
+}
+
+// UnmarshalJSON unmarshals the audio service type from its number or name
+func (t *AudioServiceType) UnmarshalJSON(b []byte) error {
+	value, err := unmarshalEnum(b, audioServiceTypeNames)
+	*t = AudioServiceType(value)
+	return err
+}
@@ -0,0 +1,79 @@
+package ffprobe
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func Test_AudioSideData(t *testing.T) {
+	var list SideDataList
+	// Stream side data as printed by ffprobe -show_streams, and frame side data as printed by -show_frames
+	err := json.Unmarshal([]byte(`[
+		{
+			"side_data_type": "Replay Gain",
+			"track_gain": "-720000/100000",
+			"track_peak": "98800/100000",
+			"album_gain": "unknown",
+			"album_peak": "unknown"
+		},
+		{
+			"side_data_type": "Metadata relevant to a downmix procedure",
+			"preferred_downmix_type": 2,
+			"center_mix_level": 0.707107,
+			"surround_mix_level": "0.500000",
+			"lfe_mix_level": 0
+		},
+		{
+			"side_data_type": "Audio Service Type",
+			"service_type": 5
+		}
+	]`), &list)
+	if err != nil {
+		t.Fatalf("Error unmarshalling side data: %v", err)
+	}
+
+	replayGain, err := list.GetReplayGain()
+	if err != nil {
+		t.Fatalf("Error getting ReplayGain: %v", err)
+	}
+	if replayGain.TrackGain != (OptionalFloat{Value: -7.2, Valid: true}) ||
+		replayGain.TrackPeak != (OptionalFloat{Value: 0.988, Valid: true}) {
+		t.Errorf("Expected track gain -7.2 and peak 0.988, got %+v", replayGain)
+	}
+	if replayGain.AlbumGain.Valid || replayGain.AlbumPeak.Valid {
+		t.Errorf("Expected unknown album gain and peak, got %+v", replayGain)
+	}
+
+	downmix, err := list.GetDownmixInfo()
+	if err != nil {
+		t.Fatalf("Error getting downmix info: %v", err)
+	}
+	if downmix.PreferredDownmixType != DownmixTypeLtRt || downmix.PreferredDownmixType.String() != "lt_rt" {
+		t.Errorf("Expected Lt/Rt downmix, got %v", downmix.PreferredDownmixType)
+	}
+	if downmix.CenterMixLevel.Value != 0.707107 || downmix.SurroundMixLevel.Value != 0.5 ||
+		!downmix.LFEMixLevel.Valid || downmix.CenterMixLevelLtRt.Valid {
+		t.Errorf("Unexpected mix levels %+v", downmix)
+	}
+
+	serviceType, err := list.GetAudioServiceType()
+	if err != nil {
+		t.Fatalf("Error getting audio service type: %v", err)
+	}
+	if serviceType.ServiceType != AudioServiceTypeCommentary || serviceType.ServiceType.String() != "commentary" {
+		t.Errorf("Expected commentary service type, got %v", serviceType.ServiceType)
+	}
+
+	// Round trip, as used by the disk cache
+	b, err := json.Marshal(list)
+	if err != nil {
+		t.Fatalf("Error marshalling side data: %v", err)
+	}
+	var roundTrip SideDataList
+	if err := json.Unmarshal(b, &roundTrip); err != nil {
+		t.Fatalf("Error unmarshalling side data: %v", err)
+	}
+	if rg, _ := roundTrip.GetReplayGain(); rg == nil || *rg != *replayGain {
+		t.Errorf("Expected %+v after round trip, got %+v", replayGain, rg)
+	}
+}
@@ -0,0 +1,205 @@
+package ffprobe
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// processLimiter limits the amount of ffprobe processes running concurrently for all batches
+var processLimiter = newSemaphore(runtime.NumCPU())
+
+// SetMaxConcurrentProbes sets the global maximum amount of ffprobe processes run concurrently by ProbeBatch, shared
+// by all batches. It defaults to the number of CPUs.
+func SetMaxConcurrentProbes(max int) {
+	if max < 1 {
+		max = 1
+	}
+	processLimiter.setLimit(max)
+}
+
+// BatchOptions are the options for ProbeBatch
+type BatchOptions struct {
+	// Concurrency is the maximum amount of inputs of this batch probed concurrently. The global limit set using
+	// SetMaxConcurrentProbes applies as well. Defaults to the number of CPUs.
+	Concurrency int
+	// ItemTimeout is the timeout for probing a single input, zero means no timeout. Time spent waiting for the global
+	// limit set using SetMaxConcurrentProbes is not included.
+	ItemTimeout time.Duration
+	// FailFast stops the batch at the first error. The results of inputs that were not probed have the error of
+	// the context as error. When false, all inputs are probed regardless of errors.
+	FailFast bool
+	// Progress is called after each input was probed, with the amount of inputs completed so far. It is never called
+	// concurrently.
+	Progress func(completed, total int, result *BatchResult)
+	// ExtraFFProbeOptions are supplied to ffprobe for every input
+	ExtraFFProbeOptions []string
+}
+
+// BatchResult is the result of probing a single input of a batch
+type BatchResult struct {
+	Input string
+	Data  *ProbeData
+	Err   error
+}
+
+// ProbeBatch probes the given inputs concurrently using ProbeURL. The results are in the same order as the inputs.
+// With FailFast, the first error is returned as well. Otherwise, an error is only returned when the context is done
+// before all inputs are probed.
+func ProbeBatch(ctx context.Context, inputs []string, opts *BatchOptions) ([]BatchResult, error) {
+	return defaultProber.ProbeBatch(ctx, inputs, opts)
+}
+
+// ProbeBatch probes the given inputs concurrently using the configuration of the Prober.
+// See the package level ProbeBatch function for more details.
+func (p *Prober) ProbeBatch(ctx context.Context, inputs []string, opts *BatchOptions) ([]BatchResult, error) {
+	if opts == nil {
+		opts = &BatchOptions{}
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+
+	ctx, cancelFn := context.WithCancel(ctx)
+	defer cancelFn()
+
+	results := make([]BatchResult, len(inputs))
+	for i := range inputs {
+		results[i].Input = inputs[i]
+	}
+
+	var (
+		mu        sync.Mutex
+		completed int
+		firstErr  error
+		wg        sync.WaitGroup
+	)
+	finish := func(result *BatchResult) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		completed++
+		if result.Err != nil && opts.FailFast && firstErr == nil {
+			firstErr = result.Err
+			cancelFn()
+		}
+		if opts.Progress != nil {
+			opts.Progress(completed, len(inputs), result)
+		}
+	}
+
+	indexes := make(chan int)
+	for w := 0; w < concurrency && w < len(inputs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result := &results[i]
+				result.Data, result.Err = p.probeBatchItem(ctx, result.Input, opts)
+				finish(result)
+			}
+		}()
+	}
+
+	for i := range inputs {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	// Mark the inputs that were never probed
+	for i := range results {
+		if results[i].Data == nil && results[i].Err == nil {
+			results[i].Err = ctx.Err()
+		}
+	}
+
+	if firstErr != nil {
+		return results, firstErr
+	}
+	return results, ctx.Err()
+}
+
+func (p *Prober) probeBatchItem(ctx context.Context, input string, opts *BatchOptions) (*ProbeData, error) {
+	// Waiting for a free slot does not count towards the item timeout
+	err := processLimiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer processLimiter.release()
+
+	if opts.ItemTimeout > 0 {
+		var cancelFn context.CancelFunc
+		ctx, cancelFn = context.WithTimeout(ctx, opts.ItemTimeout)
+		defer cancelFn()
+	}
+
+	return p.ProbeURL(ctx, input, opts.ExtraFFProbeOptions...)
+}
+
+// semaphore is a counting semaphore with a limit that can be changed at any time
+type semaphore struct {
+	mu     sync.Mutex
+	limit  int
+	active int
+	wait   chan struct{}
+}
+
+func newSemaphore(limit int) *semaphore {
+	return &semaphore{limit: limit}
+}
+
+func (s *semaphore) setLimit(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limit = limit
+	s.wakeWaiters()
+}
+
+// acquire blocks until a slot is available or the context is done
+func (s *semaphore) acquire(ctx context.Context) error {
+	for {
+		s.mu.Lock()
+		if s.active < s.limit {
+			s.active++
+			s.mu.Unlock()
+			return nil
+		}
+		if s.wait == nil {
+			s.wait = make(chan struct{})
+		}
+		wait := s.wait
+		s.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *semaphore) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.active--
+	s.wakeWaiters()
+}
+
+// wakeWaiters lets all waiting goroutines try to acquire again, the lock must be held
+func (s *semaphore) wakeWaiters() {
+	if s.wait != nil {
+		close(s.wait)
+		s.wait = nil
+	}
+}
@@ -0,0 +1,180 @@
+//go:build !windows
+// +build !windows
+
+package ffprobe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeBatchProbe fails for inputs containing "bad" and echoes the input as format name otherwise
+const fakeBatchProbe = `for last; do :; done
+case "$last" in *bad*) echo "bad input" >&2; exit 1;; esac
+sleep 0.05
+echo "{\"format\":{\"filename\":\"$last\"}}"`
+
+func Test_ProbeBatch(t *testing.T) {
+	defer useFakeFFProbe(t, fakeBatchProbe)()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFn()
+
+	var inputs []string
+	for i := 0; i < 20; i++ {
+		if i == 7 {
+			inputs = append(inputs, "bad.mp4")
+			continue
+		}
+		inputs = append(inputs, fmt.Sprintf("file%d.mp4", i))
+	}
+
+	var progressCalls int
+	results, err := ProbeBatch(ctx, inputs, &BatchOptions{
+		Concurrency: 4,
+		Progress: func(completed, total int, result *BatchResult) {
+			progressCalls++
+			if completed != progressCalls || total != len(inputs) {
+				t.Errorf("Unexpected progress %d/%d", completed, total)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected batch error: %v", err)
+	}
+	if progressCalls != len(inputs) {
+		t.Errorf("Expected %d progress calls, got %d", len(inputs), progressCalls)
+	}
+
+	for i, result := range results {
+		if result.Input != inputs[i] {
+			t.Errorf("Result %d has input %s, expected %s", i, result.Input, inputs[i])
+		}
+		if i == 7 {
+			if result.Err == nil {
+				t.Errorf("Expected an error for the bad input")
+			}
+			continue
+		}
+		if result.Err != nil {
+			t.Errorf("Unexpected error for %s: %v", result.Input, result.Err)
+		} else if result.Data.Format.Filename != inputs[i] {
+			t.Errorf("Result %d has data for %s", i, result.Data.Format.Filename)
+		}
+	}
+}
+
+func Test_ProbeBatch_FailFast(t *testing.T) {
+	defer useFakeFFProbe(t, fakeBatchProbe)()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFn()
+
+	inputs := []string{"bad.mp4"}
+	for i := 0; i < 50; i++ {
+		inputs = append(inputs, fmt.Sprintf("file%d.mp4", i))
+	}
+
+	results, err := ProbeBatch(ctx, inputs, &BatchOptions{Concurrency: 1, FailFast: true})
+	if err == nil {
+		t.Fatalf("Expected an error")
+	}
+	if results[0].Err != err {
+		t.Errorf("Expected the error of the first input, got %v", err)
+	}
+	if !errors.Is(results[len(results)-1].Err, context.Canceled) {
+		t.Errorf("Expected the last input not to be probed, got %v", results[len(results)-1].Err)
+	}
+}
+
+func Test_ProbeBatch_ItemTimeout(t *testing.T) {
+	defer useFakeFFProbe(t, "sleep 5")()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFn()
+
+	start := time.Now()
+	results, err := ProbeBatch(ctx, []string{"a.mp4", "b.mp4"}, &BatchOptions{ItemTimeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Unexpected batch error: %v", err)
+	}
+	for _, result := range results {
+		if result.Err == nil {
+			t.Errorf("Expected a timeout error for %s", result.Input)
+		}
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Errorf("Item timeout was not applied")
+	}
+}
+
+func Test_ProbeBatch_ItemTimeoutExcludesWaiting(t *testing.T) {
+	defer useFakeFFProbe(t, fakeBatchProbe+"\nsleep 0.4")()
+
+	SetMaxConcurrentProbes(1)
+	defer SetMaxConcurrentProbes(runtime.NumCPU())
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFn()
+
+	// Each probe fits its timeout, but waiting for the others does not
+	inputs := []string{"a.mp4", "b.mp4", "c.mp4"}
+	results, err := ProbeBatch(ctx, inputs, &BatchOptions{Concurrency: 3, ItemTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("Unexpected batch error: %v", err)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("Unexpected error for %s: %v", result.Input, result.Err)
+		}
+	}
+}
+
+func Test_semaphore(t *testing.T) {
+	ctx := context.Background()
+	sem := newSemaphore(3)
+
+	var active, maxActive int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sem.acquire(ctx); err != nil {
+				t.Errorf("Error acquiring: %v", err)
+				return
+			}
+			n := atomic.AddInt32(&active, 1)
+			for {
+				m := atomic.LoadInt32(&maxActive)
+				if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&active, -1)
+			sem.release()
+		}()
+	}
+	wg.Wait()
+
+	if maxActive != 3 {
+		t.Errorf("Expected at most 3 concurrent holders, got %d", maxActive)
+	}
+
+	// A full semaphore respects the context
+	for i := 0; i < 3; i++ {
+		_ = sem.acquire(ctx)
+	}
+	timeoutCtx, cancelFn := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancelFn()
+	if err := sem.acquire(timeoutCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline error, got %v", err)
+	}
+}
@@ -0,0 +1,303 @@
+package ffprobe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ErrInvalidBitrateWindow is returned when a bitrate window duration is not positive, or too short for the duration of
+// the file
+var ErrInvalidBitrateWindow = errors.New("invalid bitrate window")
+
+// BitrateMode classifies the bitrate of a stream as constant or variable
+type BitrateMode string
+
+const (
+	// BitrateConstant means the bitrate barely varies over time
+	BitrateConstant BitrateMode = "cbr"
+	// BitrateVariable means the bitrate varies over time
+	BitrateVariable BitrateMode = "vbr"
+)
+
+// BitrateOptions are the options for AnalyzeBitrate
+type BitrateOptions struct {
+	// Windows are the window durations to compute the bitrate over, for example 1s and the segment duration.
+	// Every duration must be positive. Defaults to a single window of 1 second.
+	Windows []time.Duration
+	// CBRTolerance is the maximum coefficient of variation (standard deviation divided by the mean) of the bitrate
+	// for a stream to be classified as constant bitrate. Defaults to 0.1.
+	CBRTolerance float64
+}
+
+// BitrateReport is the bitrate over time of every stream, and of all streams together
+type BitrateReport struct {
+	// Streams are the bitrates of the streams, ordered by stream index
+	Streams []StreamBitrate
+	// Total is the bitrate of all streams together, its stream index is -1
+	Total StreamBitrate
+}
+
+// StreamBitrate is the bitrate over time of a single stream
+type StreamBitrate struct {
+	StreamIndex int
+	// Average is the average bitrate in bits per second
+	Average int64
+	// Windows are the statistics for every configured window duration
+	Windows []WindowBitrate
+}
+
+// WindowBitrate are the bitrate statistics computed over windows of a certain duration. All bitrates are in bits per
+// second. The last window is left out of the statistics when there are more, as it is usually cut short by the end of
+// the stream.
+type WindowBitrate struct {
+	// Window is the duration of the windows
+	Window time.Duration
+	// Series is the bitrate of consecutive windows, starting at the first packet of the file
+	Series []int64
+	// Peak is the highest bitrate in the series
+	Peak int64
+	// RollingPeak is the highest bitrate of any window of this duration, not just the consecutive ones in the series.
+	// It is the strictest peak, suitable for buffer sizing.
+	RollingPeak int64
+	// P50, P90, P95 and P99 are percentiles of the series
+	P50 int64
+	P90 int64
+	P95 int64
+	P99 int64
+	// Mode classifies the bitrate as constant or variable
+	Mode BitrateMode
+}
+
+// AnalyzeBitrate computes the bitrate over time of every stream from the sizes and timestamps of its packets.
+// No decoding is needed, but every packet of the file is read.
+func AnalyzeBitrate(ctx context.Context, fileURL string, opts *BitrateOptions) (*BitrateReport, error) {
+	return defaultProber.AnalyzeBitrate(ctx, fileURL, opts)
+}
+
+// AnalyzeBitrate computes the bitrate over time of every stream, using the configuration of the Prober.
+// See the package level AnalyzeBitrate function for more details.
+func (p *Prober) AnalyzeBitrate(ctx context.Context, fileURL string, opts *BitrateOptions) (*BitrateReport, error) {
+	windows := []time.Duration{time.Second}
+	tolerance := 0.1
+	if opts != nil {
+		if len(opts.Windows) > 0 {
+			windows = opts.Windows
+		}
+		for _, window := range windows {
+			if window <= 0 {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidBitrateWindow, window)
+			}
+		}
+		if opts.CBRTolerance > 0 {
+			tolerance = opts.CBRTolerance
+		}
+	}
+
+	args := []string{
+		"-show_entries", "packet=stream_index,pts_time,dts_time,duration_time,size",
+	}
+
+	streams := make(map[int]*bitrateAnalyzer)
+	err := p.streamEntries(ctx, fileURL, args, func(e entries) error {
+		if e[sectionKey] != "packet" {
+			return nil
+		}
+		index, ok := e.int("stream_index")
+		if !ok {
+			return nil
+		}
+		// The decoding timestamp always increases within a stream, unlike the presentation timestamp
+		ts, ok := e.duration("dts_time")
+		if !ok {
+			ts, ok = e.duration("pts_time")
+			if !ok {
+				return nil
+			}
+		}
+		size, _ := e.int("size")
+		duration, _ := e.duration("duration_time")
+
+		stream := streams[int(index)]
+		if stream == nil {
+			stream = &bitrateAnalyzer{}
+			streams[int(index)] = stream
+		}
+		stream.add(ts, duration, size*8)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Packets of different streams are not necessarily in timestamp order, so the total is computed afterwards
+	total := &bitrateAnalyzer{}
+	for _, stream := range streams {
+		total.merge(stream)
+	}
+
+	report := &BitrateReport{}
+	report.Total, err = total.result(-1, total.first, windows, tolerance)
+	if err != nil {
+		return nil, err
+	}
+	for index, stream := range streams {
+		result, err := stream.result(index, total.first, windows, tolerance)
+		if err != nil {
+			return nil, err
+		}
+		report.Streams = append(report.Streams, result)
+	}
+	sort.Slice(report.Streams, func(i, j int) bool {
+		return report.Streams[i].StreamIndex < report.Streams[j].StreamIndex
+	})
+	return report, nil
+}
+
+// maxBitrateSeries is the maximum length of a bitrate series, so a bogus timestamp cannot exhaust the memory
+const maxBitrateSeries = 1 << 20
+
+// bitrateAnalyzer collects the packets of a single stream, or of all streams
+type bitrateAnalyzer struct {
+	packets []packetBits
+	bits    int64
+	first   time.Duration
+	end     time.Duration
+}
+
+type packetBits struct {
+	ts   time.Duration
+	bits int64
+}
+
+// add adds a packet with the given timestamp
+func (a *bitrateAnalyzer) add(ts, duration time.Duration, bits int64) {
+	if len(a.packets) == 0 || ts < a.first {
+		a.first = ts
+	}
+	if len(a.packets) == 0 || ts+duration > a.end {
+		a.end = ts + duration
+	}
+	a.bits += bits
+	a.packets = append(a.packets, packetBits{ts: ts, bits: bits})
+}
+
+// merge adds all packets of the other analyzer
+func (a *bitrateAnalyzer) merge(other *bitrateAnalyzer) {
+	if len(other.packets) == 0 {
+		return
+	}
+	if len(a.packets) == 0 || other.first < a.first {
+		a.first = other.first
+	}
+	if len(a.packets) == 0 || other.end > a.end {
+		a.end = other.end
+	}
+	a.bits += other.bits
+	a.packets = append(a.packets, other.packets...)
+}
+
+// result computes the bitrates, with series starting at the given origin
+func (a *bitrateAnalyzer) result(index int, origin time.Duration, windows []time.Duration, tolerance float64) (StreamBitrate, error) {
+	result := StreamBitrate{StreamIndex: index}
+	if duration := a.end - a.first; duration > 0 {
+		result.Average = int64(float64(a.bits) / duration.Seconds())
+	}
+
+	sort.SliceStable(a.packets, func(i, j int) bool {
+		return a.packets[i].ts < a.packets[j].ts
+	})
+	for _, window := range windows {
+		windowResult, err := a.windowResult(origin, window, tolerance)
+		if err != nil {
+			return result, err
+		}
+		result.Windows = append(result.Windows, windowResult)
+	}
+	return result, nil
+}
+
+// windowResult computes the bitrate statistics over windows of the given duration, the packets must be sorted
+func (a *bitrateAnalyzer) windowResult(origin, window time.Duration, tolerance float64) (WindowBitrate, error) {
+	result := WindowBitrate{
+		Window: window,
+		Mode:   BitrateConstant,
+	}
+	if len(a.packets) == 0 {
+		return result, nil
+	}
+
+	count := int64((a.packets[len(a.packets)-1].ts-origin)/window) + 1
+	if count > maxBitrateSeries {
+		return result, fmt.Errorf("%w: %v is too short for a stream of %v", ErrInvalidBitrateWindow, window,
+			a.packets[len(a.packets)-1].ts-origin)
+	}
+	buckets := make([]int64, count)
+
+	// The rolling window holds the packets within the window duration before the current packet
+	var rollingStart int
+	var rollingBits, rollingPeak int64
+	for _, packet := range a.packets {
+		buckets[(packet.ts-origin)/window] += packet.bits
+
+		rollingBits += packet.bits
+		for a.packets[rollingStart].ts <= packet.ts-window {
+			rollingBits -= a.packets[rollingStart].bits
+			rollingStart++
+		}
+		if rollingBits > rollingPeak {
+			rollingPeak = rollingBits
+		}
+	}
+	result.RollingPeak = bitsPerSecond(rollingPeak, window)
+
+	result.Series = make([]int64, len(buckets))
+	for i, bits := range buckets {
+		result.Series[i] = bitsPerSecond(bits, window)
+	}
+
+	stats := result.Series
+	if len(stats) > 1 {
+		stats = stats[:len(stats)-1]
+	}
+
+	sorted := make([]int64, len(stats))
+	copy(sorted, stats)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	result.Peak = sorted[len(sorted)-1]
+	result.P50 = percentile(sorted, 50)
+	result.P90 = percentile(sorted, 90)
+	result.P95 = percentile(sorted, 95)
+	result.P99 = percentile(sorted, 99)
+
+	var sum, squares float64
+	for _, rate := range stats {
+		sum += float64(rate)
+	}
+	mean := sum / float64(len(stats))
+	for _, rate := range stats {
+		squares += (float64(rate) - mean) * (float64(rate) - mean)
+	}
+	if mean > 0 && math.Sqrt(squares/float64(len(stats)))/mean > tolerance {
+		result.Mode = BitrateVariable
+	}
+	return result, nil
+}
+
+// percentile returns the nearest rank percentile of the sorted values
+func percentile(sorted []int64, p float64) int64 {
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+func bitsPerSecond(bits int64, window time.Duration) int64 {
+	return int64(float64(bits) / window.Seconds())
+}
@@ -0,0 +1,135 @@
+//go:build !windows
+// +build !windows
+
+package ffprobe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fakeBitrateOutput() string {
+	var lines []string
+	videoSizes := []int{10000, 2000, 10000, 2000, 50000, 2000, 10000, 2000}
+	for i := 0; i < 16; i++ {
+		ts := float64(i) * 0.25
+		if i%2 == 0 {
+			lines = append(lines, fmt.Sprintf("packet|stream_index=0|pts_time=%f|dts_time=%f|duration_time=0.500000|size=%d",
+				ts, ts, videoSizes[i/2]))
+		}
+		lines = append(lines, fmt.Sprintf("packet|stream_index=1|pts_time=%f|dts_time=%f|duration_time=0.250000|size=1000",
+			ts, ts))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func Test_AnalyzeBitrate(t *testing.T) {
+	defer useFakeFFProbe(t, "cat <<'EOF'\n"+fakeBitrateOutput()+"\nEOF")()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	report, err := AnalyzeBitrate(ctx, testPath, &BitrateOptions{Windows: []time.Duration{time.Second, 2 * time.Second}})
+	if err != nil {
+		t.Fatalf("Error analyzing bitrate: %v", err)
+	}
+	if len(report.Streams) != 2 || report.Streams[0].StreamIndex != 0 || report.Streams[1].StreamIndex != 1 {
+		t.Fatalf("Expected bitrates for streams 0 and 1, got %+v", report.Streams)
+	}
+
+	video := report.Streams[0]
+	if video.Average != 176000 {
+		t.Errorf("Expected video average of 176000, got %d", video.Average)
+	}
+	expected := WindowBitrate{
+		Window:      time.Second,
+		Series:      []int64{96000, 96000, 416000, 96000},
+		Peak:        416000,
+		RollingPeak: 416000,
+		P50:         96000,
+		P90:         416000,
+		P95:         416000,
+		P99:         416000,
+		Mode:        BitrateVariable,
+	}
+	if !reflect.DeepEqual(video.Windows[0], expected) {
+		t.Errorf("Expected video 1s window\n%+v, got\n%+v", expected, video.Windows[0])
+	}
+	if series := video.Windows[1].Series; !reflect.DeepEqual(series, []int64{96000, 256000}) {
+		t.Errorf("Expected video 2s series [96000 256000], got %v", series)
+	}
+
+	audio := report.Streams[1]
+	if audio.Average != 32000 || audio.Windows[0].Mode != BitrateConstant || audio.Windows[0].Peak != 32000 {
+		t.Errorf("Expected constant audio bitrate of 32000, got %+v", audio)
+	}
+
+	if report.Total.StreamIndex != -1 || report.Total.Average != 208000 {
+		t.Errorf("Expected total average of 208000, got %+v", report.Total)
+	}
+	if peak := report.Total.Windows[0].Peak; peak != 448000 {
+		t.Errorf("Expected total peak of 448000, got %d", peak)
+	}
+}
+
+func Test_AnalyzeBitrate_OutOfOrder(t *testing.T) {
+	// The audio packets are muxed a second ahead of the video packets
+	var lines []string
+	for i := 0; i < 4; i++ {
+		lines = append(lines,
+			fmt.Sprintf("packet|stream_index=1|dts_time=%d.000000|duration_time=1.000000|size=1000", i+1),
+			fmt.Sprintf("packet|stream_index=0|dts_time=%d.000000|duration_time=1.000000|size=10000", i),
+		)
+	}
+	defer useFakeFFProbe(t, "cat <<'EOF'\n"+strings.Join(lines, "\n")+"\nEOF")()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	report, err := AnalyzeBitrate(ctx, testPath, nil)
+	if err != nil {
+		t.Fatalf("Error analyzing bitrate: %v", err)
+	}
+	total := report.Total.Windows[0]
+	if !reflect.DeepEqual(total.Series, []int64{80000, 88000, 88000, 88000, 8000}) {
+		t.Errorf("Unexpected total series %v", total.Series)
+	}
+	if total.RollingPeak != 88000 {
+		t.Errorf("Expected a total rolling peak of 88000, got %d", total.RollingPeak)
+	}
+	if series := report.Streams[1].Windows[0].Series; !reflect.DeepEqual(series, []int64{0, 8000, 8000, 8000, 8000}) {
+		t.Errorf("Expected the audio series to start at the first packet of the file, got %v", series)
+	}
+}
+
+func Test_AnalyzeBitrate_WindowTooShort(t *testing.T) {
+	defer useFakeFFProbe(t, "echo 'packet|stream_index=0|dts_time=0.000000|size=1000'\n"+
+		"echo 'packet|stream_index=0|dts_time=86400.000000|size=1000'")()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	_, err := AnalyzeBitrate(ctx, testPath, &BitrateOptions{Windows: []time.Duration{time.Millisecond}})
+	if !errors.Is(err, ErrInvalidBitrateWindow) {
+		t.Errorf("Expected ErrInvalidBitrateWindow for too many windows, got %v", err)
+	}
+}
+
+func Test_AnalyzeBitrate_InvalidWindow(t *testing.T) {
+	defer useFakeFFProbe(t, "cat <<'EOF'\n"+fakeBitrateOutput()+"\nEOF")()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	for _, window := range []time.Duration{0, -time.Second} {
+		_, err := AnalyzeBitrate(ctx, testPath, &BitrateOptions{Windows: []time.Duration{time.Second, window}})
+		if !errors.Is(err, ErrInvalidBitrateWindow) {
+			t.Errorf("Expected ErrInvalidBitrateWindow for a window of %v, got %v", window, err)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package ffprobe
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Cache stores probe results. Implementations must be safe for concurrent use. The Prober copies the data going in
+// and out of the cache, so implementations may keep and return the same pointer.
+type Cache interface {
+	// Get returns the probe data stored for the key, if any
+	Get(key string) (*ProbeData, bool)
+	// Set stores the probe data for the key
+	Set(key string, data *ProbeData)
+}
+
+// cacheKey returns the cache key for a probe of a local file, or false when the URL is not a local file or the
+// file cannot be found. The key changes when the file or the ffprobe version changes.
+func cacheKey(ctx context.Context, fileURL string, args []string) (string, bool) {
+	path, ok := localPath(fileURL)
+	if !ok {
+		return "", false
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	return buildCacheKey(ctx, fmt.Sprintf("file:%s:%d:%d", abs, info.Size(), info.ModTime().UnixNano()), args)
+}
+
+// readerCacheKey returns the cache key for a probe of a reader, based on the hash of the part of its contents fed to
+// ffprobe. The reader is seeked back to where it was. False is returned when the reader cannot seek, or when no read
+// limit is in effect, as the whole reader would have to be hashed.
+func readerCacheKey(ctx context.Context, reader io.Reader, args []string) (string, bool) {
+	limited, ok := reader.(*io.LimitedReader)
+	if !ok {
+		return "", false
+	}
+	seeker, ok := limited.R.(io.ReadSeeker)
+	if !ok {
+		return "", false
+	}
+	hashed := &io.LimitedReader{R: seeker, N: limited.N}
+
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", false
+	}
+	hash := sha256.New()
+	_, err = io.Copy(hash, hashed)
+	if _, seekErr := seeker.Seek(start, io.SeekStart); err != nil || seekErr != nil {
+		return "", false
+	}
+	return buildCacheKey(ctx, "sha256:"+hex.EncodeToString(hash.Sum(nil)), args)
+}
+
+func buildCacheKey(ctx context.Context, input string, args []string) (string, bool) {
+	version, err := ffprobeVersion(ctx)
+	if err != nil {
+		return "", false
+	}
+	return strings.Join(append([]string{version, input}, args...), "\x00"), true
+}
+
+// localPath returns the path of the file the URL points to, when it is a local file
+func localPath(fileURL string) (string, bool) {
+	u, err := url.Parse(fileURL)
+	if err != nil || u.Scheme == "" || len(u.Scheme) == 1 {
+		// A plain path, or a windows path with a drive letter
+		return fileURL, fileURL != "" && fileURL != "-"
+	}
+	if strings.ToLower(u.Scheme) != "file" {
+		return "", false
+	}
+	if u.Path != "" {
+		return u.Path, true
+	}
+	return u.Opaque, u.Opaque != ""
+}
+
+// MemoryCache is a Cache keeping a limited amount of results in memory, evicting the least recently used ones
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+}
+
+type memoryCacheEntry struct {
+	key  string
+	data *ProbeData
+}
+
+// NewMemoryCache returns a new MemoryCache holding at most maxEntries results
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Get returns the probe data stored for the key, if any
+func (c *MemoryCache) Get(key string) (*ProbeData, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*memoryCacheEntry).data, true
+}
+
+// Set stores the probe data for the key
+func (c *MemoryCache) Set(key string, data *ProbeData) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*memoryCacheEntry).data = data
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&memoryCacheEntry{key: key, data: data})
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// Len returns the amount of results in the cache
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// DiskCache is a Cache storing every result as a JSON file in a directory. Errors reading or writing the files are
+// treated as cache misses. Entries are never removed, removing old files is left to the user.
+// Only the ffprobe compatible JSON is stored, so the Warnings and Info of the probe data are lost.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache returns a new DiskCache storing its files in the given directory, which is created if needed
+func NewDiskCache(dir string) (*DiskCache, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cache directory: %w", err)
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+// Get returns the probe data stored for the key, if any
+func (c *DiskCache) Get(key string) (*ProbeData, bool) {
+	buf, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	data := &ProbeData{}
+	err = json.Unmarshal(buf, data)
+	if err != nil || data.Format == nil {
+		return nil, false
+	}
+	data.setDeprecatedTags()
+	return data, true
+}
+
+// Set stores the probe data for the key
+func (c *DiskCache) Set(key string, data *ProbeData) {
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+
+	// Write to a temporary file first, so readers never see a partially written file
+	file, err := ioutil.TempFile(c.dir, ".tmp-")
+	if err != nil {
+		return
+	}
+	_, err = file.Write(buf)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), c.path(key))
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+	}
+}
+
+func (c *DiskCache) path(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(hash[:])+".json")
+}
@@ -0,0 +1,146 @@
+//go:build !windows
+// +build !windows
+
+package ffprobe
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_ProbeURL_MemoryCache(t *testing.T) {
+	count, restore := useCountingFakeFFProbe(t, "0")
+	defer restore()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	dir, err := ioutil.TempDir("", "ffprobe-cache")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "video.mp4")
+	if err := ioutil.WriteFile(path, []byte("video"), 0o600); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+
+	prober := &Prober{Cache: NewMemoryCache(10)}
+	for i := 0; i < 3; i++ {
+		data, err := prober.ProbeURL(ctx, path)
+		if err != nil || data.Format == nil {
+			t.Fatalf("Error getting data: %v", err)
+		}
+	}
+	if n := count(); n != 1 {
+		t.Errorf("Expected 1 ffprobe invocation, got %d", n)
+	}
+
+	// Other arguments are cached separately
+	_, _ = prober.ProbeURL(ctx, path, "-show_chapters")
+	if n := count(); n != 2 {
+		t.Errorf("Expected 2 ffprobe invocations, got %d", n)
+	}
+
+	// Changing the file invalidates the cache
+	if err := ioutil.WriteFile(path, []byte("changed video"), 0o600); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+	_, _ = prober.ProbeURL(ctx, path)
+	if n := count(); n != 3 {
+		t.Errorf("Expected 3 ffprobe invocations, got %d", n)
+	}
+}
+
+func Test_ProbeReader_MemoryCache(t *testing.T) {
+	count, restore := useCountingFakeFFProbe(t, "0")
+	defer restore()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	prober := &Prober{Cache: NewMemoryCache(10), ReadLimit: 1 << 20}
+	for _, content := range []string{"video", "video", "other video"} {
+		reader := bytes.NewReader([]byte(content))
+		_, err := prober.ProbeReader(ctx, reader)
+		if err != nil {
+			t.Fatalf("Error getting data: %v", err)
+		}
+	}
+	if n := count(); n != 2 {
+		t.Errorf("Expected 2 ffprobe invocations, got %d", n)
+	}
+
+	// The reader is still at its original position when the result is cached
+	reader := bytes.NewReader([]byte("video"))
+	_, _ = prober.ProbeReader(ctx, reader)
+	if pos, _ := reader.Seek(0, io.SeekCurrent); pos != 0 {
+		t.Errorf("Expected reader to be at position 0, got %d", pos)
+	}
+
+	// Without a read limit the whole reader would have to be hashed, so it is not cached
+	prober.ReadLimit = 0
+	_, _ = prober.ProbeReader(ctx, bytes.NewReader([]byte("video")))
+	if n := count(); n != 3 {
+		t.Errorf("Expected 3 ffprobe invocations, got %d", n)
+	}
+}
+
+func Test_ProbeURL_DiskCache(t *testing.T) {
+	count, restore := useCountingFakeFFProbe(t, "0")
+	defer restore()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	dir, err := ioutil.TempDir("", "ffprobe-cache")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache, err := NewDiskCache(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatalf("Error creating disk cache: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		// A new prober every time, like a restarted process would
+		prober := &Prober{Cache: cache}
+		data, err := prober.ProbeURL(ctx, testPath)
+		if err != nil {
+			t.Fatalf("Error getting data: %v", err)
+		}
+		if data.Format.FormatName != "mov,mp4,m4a,3gp,3g2,mj2" {
+			t.Errorf("Unexpected format name %q", data.Format.FormatName)
+		}
+	}
+	if n := count(); n != 1 {
+		t.Errorf("Expected 1 ffprobe invocation, got %d", n)
+	}
+}
+
+func Test_MemoryCache_Eviction(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Set("a", &ProbeData{})
+	cache.Set("b", &ProbeData{})
+	cache.Get("a")
+	cache.Set("c", &ProbeData{})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("Expected least recently used entry to be evicted")
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Errorf("Expected recently used entry to be kept")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Expected 2 entries, got %d", cache.Len())
+	}
+}
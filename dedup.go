@@ -0,0 +1,110 @@
+package ffprobe
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// flightGroup deduplicates concurrent probes with the same key, so concurrent callers share a single ffprobe process
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is a probe shared by one or more callers
+type flight struct {
+	done    chan struct{}
+	data    *ProbeData
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// flightKey returns the key identifying a probe by its ffprobe binary and arguments
+func flightKey(args []string) string {
+	return binPath + "\x00" + strings.Join(args, "\x00")
+}
+
+// do runs fn once for all concurrent callers with the same key. The probe runs with a context of its own, which is
+// only cancelled once all callers waiting for it are gone, so one caller giving up does not fail the others.
+// Every caller receives its own copy of the probe data.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) (*ProbeData, error)) (*ProbeData, error) {
+	g.mu.Lock()
+	if g.flights == nil {
+		g.flights = make(map[string]*flight)
+	}
+	f, ok := g.flights[key]
+	if !ok {
+		f = g.start(ctx, key, fn)
+	}
+	f.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.data.Clone(), f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			// Nobody is interested anymore, stop the probe and let new callers start a fresh one
+			g.forget(key, f)
+			f.cancel()
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// start starts a new flight, the lock must be held. The flight keeps the values of the context of the caller starting
+// it, like tracing spans, but not its cancellation.
+func (g *flightGroup) start(callerCtx context.Context, key string, fn func(ctx context.Context) (*ProbeData, error)) *flight {
+	ctx, cancelFn := context.WithCancel(detachedContext{parent: callerCtx})
+	f := &flight{
+		done:   make(chan struct{}),
+		cancel: cancelFn,
+	}
+	g.flights[key] = f
+
+	go func() {
+		defer cancelFn()
+
+		f.data, f.err = fn(ctx)
+
+		g.mu.Lock()
+		g.forget(key, f)
+		g.mu.Unlock()
+		close(f.done)
+	}()
+	return f
+}
+
+// forget removes the flight if it is still the current flight for the key, the lock must be held
+func (g *flightGroup) forget(key string, f *flight) {
+	if g.flights[key] == f {
+		delete(g.flights, key)
+	}
+}
+
+// detachedContext keeps the values of its parent, but not its deadline and cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (c detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (c detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
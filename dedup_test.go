@@ -0,0 +1,178 @@
+//go:build !windows
+// +build !windows
+
+package ffprobe
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// useCountingFakeFFProbe installs a fake ffprobe that records every invocation in a file. The returned function
+// returns the amount of invocations.
+func useCountingFakeFFProbe(t *testing.T, delay string) (count func() int, restore func()) {
+	t.Helper()
+
+	countFile, err := ioutil.TempFile("", "ffprobe-count")
+	if err != nil {
+		t.Fatalf("Error creating count file: %v", err)
+	}
+	_ = countFile.Close()
+
+	restoreBin := useFakeFFProbe(t, `[ "$1" = "-version" ] && { echo "ffprobe version fake"; exit 0; }`+
+		"\ncat > /dev/null\necho x >> "+countFile.Name()+"\nsleep "+delay+"\necho '"+fakeProbeOutput+"'")
+	count = func() int {
+		buf, err := ioutil.ReadFile(countFile.Name())
+		if err != nil {
+			t.Fatalf("Error reading count file: %v", err)
+		}
+		return strings.Count(string(buf), "x")
+	}
+	return count, func() {
+		restoreBin()
+		_ = os.Remove(countFile.Name())
+	}
+}
+
+func Test_ProbeURL_Deduplicate(t *testing.T) {
+	count, restore := useCountingFakeFFProbe(t, "0.3")
+	defer restore()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	prober := &Prober{DeduplicateProbes: true}
+
+	const callers = 5
+	results := make([]*ProbeData, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data, err := prober.ProbeURL(ctx, testPath)
+			if err != nil {
+				t.Errorf("Error getting data: %v", err)
+			}
+			results[i] = data
+		}(i)
+	}
+	wg.Wait()
+
+	if n := count(); n != 1 {
+		t.Errorf("Expected 1 ffprobe invocation, got %d", n)
+	}
+	if results[0] == nil || results[0] == results[1] || results[0].Format == results[1].Format {
+		t.Errorf("Expected every caller to get its own copy of the data")
+	}
+
+	// A probe after the first ones completed runs again
+	_, err := prober.ProbeURL(ctx, testPath)
+	if err != nil {
+		t.Errorf("Error getting data: %v", err)
+	}
+	if n := count(); n != 2 {
+		t.Errorf("Expected 2 ffprobe invocations, got %d", n)
+	}
+}
+
+func Test_ProbeURL_DeduplicateLeaderCancelled(t *testing.T) {
+	count, restore := useCountingFakeFFProbe(t, "0.3")
+	defer restore()
+
+	prober := &Prober{DeduplicateProbes: true}
+
+	leaderCtx, leaderCancelFn := context.WithCancel(context.Background())
+	leaderDone := make(chan error)
+	go func() {
+		_, err := prober.ProbeURL(leaderCtx, testPath)
+		leaderDone <- err
+	}()
+
+	// Make sure the leader started the probe
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	followerDone := make(chan error)
+	go func() {
+		_, err := prober.ProbeURL(ctx, testPath)
+		followerDone <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	leaderCancelFn()
+
+	if err := <-leaderDone; err != context.Canceled {
+		t.Errorf("Expected the leader to be cancelled, got %v", err)
+	}
+	if err := <-followerDone; err != nil {
+		t.Errorf("Expected the follower to get data, got %v", err)
+	}
+	if n := count(); n != 1 {
+		t.Errorf("Expected 1 ffprobe invocation, got %d", n)
+	}
+}
+
+func Test_ProbeData_Clone(t *testing.T) {
+	data := &ProbeData{
+		Format: &Format{TagList: Tags{"title": "test"}},
+		Streams: []*Stream{{
+			TagList: Tags{"language": "eng"},
+			SideDataList: SideDataList{
+				{SideDataBase: SideDataBase{Type: SideDataTypeDisplayMatrix}, Data: &SideDataDisplayMatrix{Rotation: 90}},
+				{SideDataBase: SideDataBase{Type: "other"}, Data: &SideDataUnknown{"key": "value"}},
+			},
+		}},
+	}
+
+	clone := data.Clone()
+	clone.Format.TagList["title"] = "changed"
+	clone.Streams[0].TagList["language"] = "changed"
+	clone.Streams[0].SideDataList[0].Data.(*SideDataDisplayMatrix).Rotation = 180
+	(*clone.Streams[0].SideDataList[1].Data.(*SideDataUnknown))["key"] = "changed"
+
+	if data.Format.TagList["title"] != "test" || data.Streams[0].TagList["language"] != "eng" {
+		t.Errorf("Tags of the original were changed")
+	}
+	if data.Streams[0].SideDataList[0].Data.(*SideDataDisplayMatrix).Rotation != 90 {
+		t.Errorf("Side data of the original was changed")
+	}
+	if (*data.Streams[0].SideDataList[1].Data.(*SideDataUnknown))["key"] != "value" {
+		t.Errorf("Unknown side data of the original was changed")
+	}
+}
+
+type dedupTestKey struct{}
+
+func Test_ProbeURL_DeduplicateKeepsContextValues(t *testing.T) {
+	_, restore := useCountingFakeFFProbe(t, "0")
+	defer restore()
+
+	var hookValue interface{}
+	prober := &Prober{
+		DeduplicateProbes: true,
+		Hooks: &Hooks{
+			BeforeStart: func(ctx context.Context, _ *StartEvent) context.Context {
+				hookValue = ctx.Value(dedupTestKey{})
+				return ctx
+			},
+		},
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.WithValue(context.Background(), dedupTestKey{}, "span"), 3*time.Second)
+	defer cancelFn()
+
+	if _, err := prober.ProbeURL(ctx, testPath); err != nil {
+		t.Fatalf("Error getting data: %v", err)
+	}
+	if hookValue != "span" {
+		t.Errorf("Expected the hook to see the context value of the caller, got %v", hookValue)
+	}
+}
@@ -0,0 +1,174 @@
+package ffprobe
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidDisplayMatrix is returned when a display matrix cannot be parsed
+var ErrInvalidDisplayMatrix = errors.New("invalid display matrix")
+
+// DisplayMatrix is the 3x3 transformation matrix of the display matrix side data, in row major order. A point (x, y)
+// of the decoded frame is displayed at (x', y'), where x' = (m[0]x + m[3]y + m[6]) / z, y' = (m[1]x + m[4]y + m[7]) / z
+// and z = m[2]x + m[5]y + m[8]. The first two columns are 16.16 fixed point numbers, the last column is 2.30 fixed
+// point.
+type DisplayMatrix [9]int32
+
+// IdentityDisplayMatrix is the display matrix that leaves the frame as is
+var IdentityDisplayMatrix = DisplayMatrix{1 << 16, 0, 0, 0, 1 << 16, 0, 0, 0, 1 << 30}
+
+// NewDisplayMatrix returns the display matrix that rotates the frame clockwise by the given degrees, and then flips it
+func NewDisplayMatrix(rotation float64, hflip, vflip bool) DisplayMatrix {
+	radians := rotation * math.Pi / 180
+	cos, sin := math.Cos(radians), math.Sin(radians)
+	// Snap to exact values, so multiples of 90 degrees give exact matrices
+	cos, sin = math.Round(cos*(1<<16))/(1<<16), math.Round(sin*(1<<16))/(1<<16)
+
+	var values [9]float64
+	values[0], values[1] = cos, sin
+	values[3], values[4] = -sin, cos
+	values[8] = 1
+	if hflip {
+		values[0], values[3] = -values[0], -values[3]
+	}
+	if vflip {
+		values[1], values[4] = -values[1], -values[4]
+	}
+	return displayMatrixFromFloats(values)
+}
+
+// ParseDisplayMatrix parses the display matrix as printed by ffprobe, three rows of three integers prefixed with the
+// row offset, like:
+//
+//	00000000:            0       65536           0
+//	00000001:       -65536           0           0
+//	00000002:            0           0  1073741824
+func ParseDisplayMatrix(str string) (DisplayMatrix, error) {
+	var matrix DisplayMatrix
+	n := 0
+	for _, line := range strings.Split(str, "\n") {
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			line = line[i+1:]
+		}
+		for _, field := range strings.Fields(line) {
+			if n == len(matrix) {
+				return matrix, ErrInvalidDisplayMatrix
+			}
+			val, err := strconv.ParseInt(field, 10, 32)
+			if err != nil {
+				return matrix, ErrInvalidDisplayMatrix
+			}
+			matrix[n] = int32(val)
+			n++
+		}
+	}
+	if n != len(matrix) {
+		return matrix, ErrInvalidDisplayMatrix
+	}
+	return matrix, nil
+}
+
+// Matrix parses the display matrix of the side data
+func (d *SideDataDisplayMatrix) Matrix() (DisplayMatrix, error) {
+	return ParseDisplayMatrix(d.Data)
+}
+
+// String returns the display matrix in the format ffprobe prints it
+func (m DisplayMatrix) String() string {
+	var sb strings.Builder
+	sb.WriteByte('\n')
+	for row := 0; row < 3; row++ {
+		fmt.Fprintf(&sb, "%08x:  %11d %11d %11d\n", row, m[row*3], m[row*3+1], m[row*3+2])
+	}
+	return sb.String()
+}
+
+// Float returns the value of an element of the matrix, converted from fixed point
+func (m DisplayMatrix) Float(i int) float64 {
+	if i%3 == 2 {
+		return float64(m[i]) / (1 << 30)
+	}
+	return float64(m[i]) / (1 << 16)
+}
+
+// Multiply composes the display matrix with another, the result applies m first and then other
+func (m DisplayMatrix) Multiply(other DisplayMatrix) DisplayMatrix {
+	var values [9]float64
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			for k := 0; k < 3; k++ {
+				values[row*3+col] += m.Float(row*3+k) * other.Float(k*3+col)
+			}
+		}
+	}
+	return displayMatrixFromFloats(values)
+}
+
+// IsMirrored returns whether the display matrix mirrors the frame, like the matrices of front camera videos
+func (m DisplayMatrix) IsMirrored() bool {
+	return m.Float(0)*m.Float(4)-m.Float(1)*m.Float(3) < 0
+}
+
+// Scale returns the horizontal and vertical scale factors of the display matrix
+func (m DisplayMatrix) Scale() (x, y float64) {
+	return math.Hypot(m.Float(0), m.Float(3)), math.Hypot(m.Float(1), m.Float(4))
+}
+
+// Rotation returns the clockwise rotation in degrees in the range [0, 360) of the display matrix, before any
+// horizontal flip when the matrix is mirrored. It returns NaN when the matrix scales the frame to nothing.
+func (m DisplayMatrix) Rotation() float64 {
+	a, b := m.Float(0), m.Float(1)
+	if m.IsMirrored() {
+		// A horizontal flip negates the first column, undo it to find the rotation
+		a = -a
+	}
+	scaleX, scaleY := m.Scale()
+	if scaleX == 0 || scaleY == 0 {
+		return math.NaN()
+	}
+	rotation := math.Atan2(b/scaleY, a/scaleX) * 180 / math.Pi
+	if rotation < 0 {
+		rotation += 360
+	}
+	return rotation
+}
+
+// Orientation decomposes the display matrix into a clockwise rotation rounded to whole degrees, followed by flips.
+// Mirrored matrices are reported with a horizontal flip, or with a vertical flip instead of a horizontal flip and a
+// half turn. The zero Orientation is returned when the matrix scales the frame to nothing.
+func (m DisplayMatrix) Orientation() Orientation {
+	orientation, _ := m.orientation()
+	return orientation
+}
+
+func (m DisplayMatrix) orientation() (Orientation, bool) {
+	rotation := m.Rotation()
+	if math.IsNaN(rotation) {
+		return Orientation{}, false
+	}
+
+	orientation := Orientation{
+		Rotation: normalizeRotation(int(math.Round(rotation))),
+		HFlip:    m.IsMirrored(),
+	}
+	if orientation.HFlip && orientation.Rotation == 180 {
+		orientation = Orientation{VFlip: true}
+	}
+	return orientation, true
+}
+
+// displayMatrixFromFloats converts the values to fixed point
+func displayMatrixFromFloats(values [9]float64) DisplayMatrix {
+	var matrix DisplayMatrix
+	for i, val := range values {
+		if i%3 == 2 {
+			matrix[i] = int32(math.Round(val * (1 << 30)))
+		} else {
+			matrix[i] = int32(math.Round(val * (1 << 16)))
+		}
+	}
+	return matrix
+}
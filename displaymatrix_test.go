@@ -0,0 +1,62 @@
+package ffprobe
+
+import (
+	"math"
+	"testing"
+)
+
+const portraitDisplayMatrix = "\n00000000:            0       65536           0\n" +
+	"00000001:       -65536           0           0\n" +
+	"00000002:            0           0  1073741824\n"
+
+func Test_DisplayMatrix(t *testing.T) {
+	matrix, err := ParseDisplayMatrix(portraitDisplayMatrix)
+	if err != nil {
+		t.Fatalf("Error parsing display matrix: %v", err)
+	}
+	expected := DisplayMatrix{0, 65536, 0, -65536, 0, 0, 0, 0, 1 << 30}
+	if matrix != expected {
+		t.Errorf("Expected %v, got %v", [9]int32(expected), [9]int32(matrix))
+	}
+	if str := matrix.String(); str != portraitDisplayMatrix {
+		t.Errorf("Expected %q, got %q", portraitDisplayMatrix, str)
+	}
+	if NewDisplayMatrix(90, false, false) != matrix {
+		t.Errorf("Expected a rotation of 90 degrees to give %v, got %v", matrix, NewDisplayMatrix(90, false, false))
+	}
+	if rotation := matrix.Rotation(); rotation != 90 || matrix.IsMirrored() {
+		t.Errorf("Expected a rotation of 90 degrees without mirroring, got %f", rotation)
+	}
+
+	if _, err := ParseDisplayMatrix("00000000: 0 65536 0\n"); err != ErrInvalidDisplayMatrix {
+		t.Errorf("Expected ErrInvalidDisplayMatrix for a short matrix, got %v", err)
+	}
+
+	mirrored := matrix.Multiply(NewDisplayMatrix(0, true, false))
+	if !mirrored.IsMirrored() || mirrored.Orientation() != (Orientation{Rotation: 90, HFlip: true}) {
+		t.Errorf("Expected a mirrored rotation of 90 degrees, got %+v", mirrored.Orientation())
+	}
+	if mirrored != NewDisplayMatrix(90, true, false) {
+		t.Errorf("Expected composing a rotation and a flip to equal %v, got %v",
+			NewDisplayMatrix(90, true, false), mirrored)
+	}
+
+	if upsideDown := matrix.Multiply(matrix); upsideDown != NewDisplayMatrix(180, false, false) {
+		t.Errorf("Expected two quarter turns to make a half turn, got %v", upsideDown)
+	}
+	if flipped := NewDisplayMatrix(180, true, false); flipped.Orientation() != (Orientation{VFlip: true}) {
+		t.Errorf("Expected a flipped half turn to be a vertical flip, got %+v", flipped.Orientation())
+	}
+
+	scaled := NewDisplayMatrix(45, false, false).Multiply(DisplayMatrix{2 << 16, 0, 0, 0, 2 << 16, 0, 0, 0, 1 << 30})
+	if x, y := scaled.Scale(); math.Abs(x-2) > 1e-4 || math.Abs(y-2) > 1e-4 {
+		t.Errorf("Expected a scale of 2, got %f and %f", x, y)
+	}
+	if rotation := scaled.Rotation(); math.Abs(rotation-45) > 1e-3 {
+		t.Errorf("Expected a rotation of 45 degrees, got %f", rotation)
+	}
+
+	if rotation := (DisplayMatrix{}).Rotation(); !math.IsNaN(rotation) {
+		t.Errorf("Expected NaN rotation for an empty matrix, got %f", rotation)
+	}
+}
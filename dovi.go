@@ -0,0 +1,62 @@
+package ffprobe
+
+import "strconv"
+
+// Dolby Vision base layer signal compatibility IDs
+const (
+	// DOVICompatibilityNone means the base layer cannot be played without Dolby Vision, like in profile 5
+	DOVICompatibilityNone = 0
+	// DOVICompatibilityHDR10 means the base layer is HDR10, like in profile 8.1
+	DOVICompatibilityHDR10 = 1
+	// DOVICompatibilitySDR means the base layer is SDR, like in profile 8.2
+	DOVICompatibilitySDR = 2
+	// DOVICompatibilityHLG means the base layer is HLG, like in profile 8.4
+	DOVICompatibilityHLG = 4
+	// DOVICompatibilityBluRay means the base layer is Ultra HD Blu-ray HDR10, like in profile 7
+	DOVICompatibilityBluRay = 6
+)
+
+// ProfileName returns the name of the Dolby Vision profile, including the base layer compatibility for profiles that
+// have variants, like "5", "7", "8.1" or "8.4"
+func (c *SideDataDOVIConfig) ProfileName() string {
+	name := strconv.Itoa(c.DVProfile)
+	switch c.DVProfile {
+	case 8, 10:
+		name += "." + strconv.Itoa(c.DVBLSignalCompatibilityID)
+	}
+	return name
+}
+
+// BaseLayerCompatibility returns the format a player without Dolby Vision support plays the base layer as,
+// like "HDR10" or "HLG", "none" when it is not playable on its own, or "unknown"
+func (c *SideDataDOVIConfig) BaseLayerCompatibility() string {
+	switch c.DVBLSignalCompatibilityID {
+	case DOVICompatibilityNone:
+		return "none"
+	case DOVICompatibilityHDR10:
+		return "HDR10"
+	case DOVICompatibilitySDR:
+		return "SDR"
+	case DOVICompatibilityHLG:
+		return "HLG"
+	case DOVICompatibilityBluRay:
+		return "Blu-ray"
+	default:
+		return "unknown"
+	}
+}
+
+// BaseLayerHDRFormat returns the HDR format of the base layer, or an empty format when the base layer is not playable
+// on its own or its format is unknown
+func (c *SideDataDOVIConfig) BaseLayerHDRFormat() HDRFormat {
+	switch c.DVBLSignalCompatibilityID {
+	case DOVICompatibilityHDR10, DOVICompatibilityBluRay:
+		return HDRFormatHDR10
+	case DOVICompatibilitySDR:
+		return HDRFormatSDR
+	case DOVICompatibilityHLG:
+		return HDRFormatHLG
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,53 @@
+package ffprobe
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func Test_DOVIConfig(t *testing.T) {
+	var sd SideData
+	err := json.Unmarshal([]byte(`{
+		"side_data_type": "DOVI configuration record",
+		"dv_version_major": 1,
+		"dv_version_minor": 0,
+		"dv_profile": 8,
+		"dv_level": 6,
+		"rpu_present_flag": 1,
+		"el_present_flag": 0,
+		"bl_present_flag": 1,
+		"dv_bl_signal_compatibility_id": 4
+	}`), &sd)
+	if err != nil {
+		t.Fatalf("Error unmarshalling side data: %v", err)
+	}
+
+	config, err := SideDataList{sd}.GetDOVIConfig()
+	if err != nil {
+		t.Fatalf("Error getting DOVI config: %v", err)
+	}
+	expected := SideDataDOVIConfig{
+		SideDataBase:              SideDataBase{Type: SideDataTypeDOVIConfig},
+		DVVersionMajor:            1,
+		DVProfile:                 8,
+		DVLevel:                   6,
+		RPUPresentFlag:            1,
+		BLPresentFlag:             1,
+		DVBLSignalCompatibilityID: 4,
+	}
+	if *config != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *config)
+	}
+	if name := config.ProfileName(); name != "8.4" {
+		t.Errorf("Expected profile 8.4, got %s", name)
+	}
+	if compat := config.BaseLayerCompatibility(); compat != "HLG" || config.BaseLayerHDRFormat() != HDRFormatHLG {
+		t.Errorf("Expected HLG base layer, got %s", compat)
+	}
+
+	profile5 := SideDataDOVIConfig{DVProfile: 5}
+	if profile5.ProfileName() != "5" || profile5.BaseLayerCompatibility() != "none" {
+		t.Errorf("Expected profile 5 without a compatible base layer, got %s and %s",
+			profile5.ProfileName(), profile5.BaseLayerCompatibility())
+	}
+}
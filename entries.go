@@ -0,0 +1,106 @@
+package ffprobe
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// entries are the key value pairs of a single packet or frame printed by ffprobe in the compact output format
+type entries map[string]string
+
+// sectionKey is the key the section name of the entries is stored under, like "packet" or "frame"
+const sectionKey = "section"
+
+// duration returns the value of a *_time entry as a time.Duration
+func (e entries) duration(key string) (time.Duration, bool) {
+	secs, err := strconv.ParseFloat(e[key], 64)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(math.Round(secs * float64(time.Second))), true
+}
+
+// int returns the value of an entry as an int64
+func (e entries) int(key string) (int64, bool) {
+	val, err := strconv.ParseInt(e[key], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return val, true
+}
+
+// streamEntries runs ffprobe for the URL with the given arguments, calling fn for every packet or frame printed.
+// The output is processed while ffprobe runs, so it is never buffered in full. Any error fn returns stops ffprobe.
+func (p *Prober) streamEntries(ctx context.Context, fileURL string, args []string, fn func(e entries) error) error {
+	args = append([]string{
+		"-loglevel", p.logLevel(),
+		"-print_format", "compact",
+	}, args...)
+
+	urlArgs, err := p.urlArgs(ctx, fileURL)
+	if err != nil {
+		return err
+	}
+	args = append(args, urlArgs...)
+
+	writer := &lineWriter{fn: func(line []byte) error {
+		return fn(parseCompactLine(line))
+	}}
+	return p.execute(ctx, args, nil, writer, func(_ *execution) error {
+		return writer.flush()
+	})
+}
+
+// parseCompactLine parses a line like "packet|pts_time=0.040000|size=1234|flags=K_" into its entries
+func parseCompactLine(line []byte) entries {
+	e := make(entries)
+	for i, field := range strings.Split(string(line), "|") {
+		idx := strings.IndexByte(field, '=')
+		if idx < 0 {
+			if i == 0 {
+				e[sectionKey] = field
+			}
+			continue
+		}
+		e[field[:idx]] = field[idx+1:]
+	}
+	return e
+}
+
+// lineWriter calls fn for every complete non-empty line written to it
+type lineWriter struct {
+	fn  func(line []byte) error
+	buf []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		idx := bytes.IndexByte(w.buf, '\n')
+		if idx < 0 {
+			break
+		}
+		line := bytes.TrimRight(w.buf[:idx], "\r")
+		if len(line) > 0 {
+			if err := w.fn(line); err != nil {
+				return 0, err
+			}
+		}
+		w.buf = w.buf[idx+1:]
+	}
+	return len(p), nil
+}
+
+// flush processes the last line when it was not terminated by a newline
+func (w *lineWriter) flush() error {
+	line := bytes.TrimSpace(w.buf)
+	w.buf = nil
+	if len(line) == 0 {
+		return nil
+	}
+	return w.fn(line)
+}
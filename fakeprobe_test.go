@@ -0,0 +1,38 @@
+//go:build !windows
+// +build !windows
+
+package ffprobe
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeProbeOutput is a minimal valid ffprobe output
+const fakeProbeOutput = `{"streams":[],"format":{"filename":"-","nb_streams":0,"format_name":"mov,mp4,m4a,3gp,3g2,mj2"}}`
+
+// useFakeFFProbe installs a shell script as the ffprobe binary. The returned function restores the original binary
+// and removes the script.
+func useFakeFFProbe(t *testing.T, script string) func() {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "fake-ffprobe")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+
+	path := filepath.Join(dir, "ffprobe")
+	err = ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o700) //nolint:gosec
+	if err != nil {
+		t.Fatalf("Error writing fake ffprobe: %v", err)
+	}
+
+	oldBinPath := binPath
+	SetFFProbeBinPath(path)
+	return func() {
+		SetFFProbeBinPath(oldBinPath)
+		_ = os.RemoveAll(dir)
+	}
+}
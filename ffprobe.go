@@ -1,40 +1,95 @@
 package ffprobe
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
+	"time"
 )
 
 var binPath = "ffprobe"
 
+// ErrNoFormat is returned when the output of ffprobe does not contain any format data
+var ErrNoFormat = errors.New("no format data found in ffprobe output")
+
 // SetFFProbeBinPath sets the global path to find and execute the ffprobe program
 func SetFFProbeBinPath(newBinPath string) {
 	binPath = newBinPath
 }
 
+// Prober holds the configuration used when executing ffprobe. The zero value is ready to use and behaves exactly
+// like the package level ProbeURL and ProbeReader functions. A Prober must not be copied after first use.
+type Prober struct {
+	// ReadLimit caps the amount of bytes ProbeReader feeds to the stdin of ffprobe. Once the limit is reached the
+	// stdin is closed, as if the input ended there. Zero means no limit.
+	ReadLimit int64
+
+	// KillGracePeriod is the time ffprobe and the processes it spawned are given to exit after the context is done.
+	// The processes are first asked to exit (SIGTERM on unix), and killed once the grace period is over. Zero means
+	// the processes are killed immediately.
+	KillGracePeriod time.Duration
+
+	// ResourceLimits are applied to the ffprobe process when set, see ResourceLimits.
+	ResourceLimits *ResourceLimits
+
+	// MaxOutputSize caps the amount of bytes ffprobe may write to stdout and to stderr. When exceeded, ffprobe is
+	// stopped and ErrOutputLimit is returned. Zero means no limit.
+	MaxOutputSize int64
+
+	// URLPolicy restricts the URLs ProbeURL accepts when set, and prevents ffprobe from opening any other resources
+	// than the URL or reader being probed. See URLPolicy.
+	URLPolicy *URLPolicy
+
+	// HTTPOptions are used by ProbeURL when probing HTTP(S) URLs, when set. See HTTPOptions.
+	HTTPOptions *HTTPOptions
+
+	// DeduplicateProbes makes concurrent calls to ProbeURL with the same URL and arguments share a single ffprobe
+	// process. Every caller receives its own copy of the result. The shared process runs with the context values of
+	// the caller that started it, so Hooks only see the values of that caller.
+	DeduplicateProbes bool
+
+	// Cache stores the results of probes of local files and seekable readers when set. Results are keyed by the
+	// file identity (path, size and modification time) or the contents of the reader, the ffprobe version and the
+	// arguments, so a changed file is probed again. See NewMemoryCache and NewDiskCache.
+	//
+	// Readers are only cached when ReadLimit is set. Their key is a hash of up to ReadLimit bytes, which are read
+	// before probing and then read again by ffprobe, so a large ReadLimit doubles the cost of probing a reader.
+	Cache Cache
+
+	// Retry retries probes of ProbeURL that failed with a transient error when set, see RetryPolicy.
+	Retry *RetryPolicy
+
+	// Hooks are called around every ffprobe invocation when set, see Hooks.
+	Hooks *Hooks
+
+	// Logger logs every ffprobe invocation when set. Successful invocations are logged at debug level, failed ones
+	// at warn level. A *slog.Logger can be used.
+	Logger Logger
+
+	// LogLevel is the log level ffprobe runs at. Anything logged is parsed into the Warnings of the probe data, even
+	// when the probe succeeds. Defaults to fatal, in which case nothing is collected.
+	// When Retry is set, a level quieter than error is raised to error, as errors are needed to detect transient
+	// failures.
+	LogLevel LogLevel
+
+	// CollectInfo attaches a ProbeInfo describing how the data was produced to the probe data, see ProbeInfo.
+	CollectInfo bool
+
+	flights flightGroup
+}
+
+var defaultProber = &Prober{}
+
 // ProbeURL is used to probe the given media file using ffprobe. The URL can be a local path, a HTTP URL or any other
 // protocol supported by ffprobe, see here for a full list: https://ffmpeg.org/ffmpeg-protocols.html
 // This function takes a context to allow killing the ffprobe process if it takes too long or in case of shutdown.
 // Any additional ffprobe parameter can be supplied as well using extraFFProbeOptions.
 func ProbeURL(ctx context.Context, fileURL string, extraFFProbeOptions ...string) (data *ProbeData, err error) {
-	args := append([]string{
-		"-loglevel", "fatal",
-		"-print_format", "json",
-		"-show_format",
-		"-show_streams",
-	}, extraFFProbeOptions...)
-
-	// Add the file argument
-	args = append(args, fileURL)
-
-	cmd := exec.CommandContext(ctx, binPath, args...)
-	cmd.SysProcAttr = procAttributes()
-
-	return runProbe(cmd)
+	return defaultProber.ProbeURL(ctx, fileURL, extraFFProbeOptions...)
 }
 
 // ProbeReader is used to probe a media file using an io.Reader. The reader is piped to the stdin of the ffprobe command
@@ -42,54 +97,376 @@ func ProbeURL(ctx context.Context, fileURL string, extraFFProbeOptions ...string
 // This function takes a context to allow killing the ffprobe process if it takes too long or in case of shutdown.
 // Any additional ffprobe parameter can be supplied as well using extraFFProbeOptions.
 func ProbeReader(ctx context.Context, reader io.Reader, extraFFProbeOptions ...string) (data *ProbeData, err error) {
-	args := append([]string{
-		"-loglevel", "fatal",
-		"-print_format", "json",
-		"-show_format",
-		"-show_streams",
-	}, extraFFProbeOptions...)
+	return defaultProber.ProbeReader(ctx, reader, extraFFProbeOptions...)
+}
+
+// ProbeURL is used to probe the given media file using ffprobe, using the configuration of the Prober.
+// See the package level ProbeURL function for more details.
+func (p *Prober) ProbeURL(ctx context.Context, fileURL string, extraFFProbeOptions ...string) (data *ProbeData, err error) {
+	args := append(p.probeArgs(), extraFFProbeOptions...)
+
+	urlArgs, err := p.urlArgs(ctx, fileURL)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, urlArgs...)
+
+	probe := func() (*ProbeData, error) {
+		if p.DeduplicateProbes {
+			return p.flights.do(ctx, flightKey(args), func(ctx context.Context) (*ProbeData, error) {
+				return p.runProbeWithRetry(ctx, args)
+			})
+		}
+		return p.runProbeWithRetry(ctx, args)
+	}
+
+	if p.Cache != nil {
+		if key, ok := cacheKey(ctx, fileURL, args); ok {
+			return p.cached(key, probe)
+		}
+	}
+	return probe()
+}
+
+// ProbeReader is used to probe a media file using an io.Reader, using the configuration of the Prober.
+// See the package level ProbeReader function for more details.
+//
+// ffprobe often exits before it has consumed all of its input. This is not treated as an error: copying from the
+// reader stops as soon as ffprobe is done. When reading from the reader fails, an *InputReadError is returned.
+func (p *Prober) ProbeReader(ctx context.Context, reader io.Reader, extraFFProbeOptions ...string) (data *ProbeData, err error) {
+	args := append(p.probeArgs(), extraFFProbeOptions...)
+
+	if p.URLPolicy != nil {
+		args = append(args, p.URLPolicy.args([]string{"pipe"})...)
+	}
 
 	// Add the file from stdin argument
 	args = append(args, "-")
 
-	cmd := exec.CommandContext(ctx, binPath, args...)
-	cmd.Stdin = reader
-	cmd.SysProcAttr = procAttributes()
+	if p.ReadLimit > 0 {
+		reader = io.LimitReader(reader, p.ReadLimit)
+	}
 
-	return runProbe(cmd)
+	if p.Cache != nil {
+		if key, ok := readerCacheKey(ctx, reader, args); ok {
+			return p.cached(key, func() (*ProbeData, error) {
+				return p.runProbe(ctx, args, reader)
+			})
+		}
+	}
+	return p.runProbe(ctx, args, reader)
 }
 
-// runProbe takes the fully configured ffprobe command and executes it, returning the ffprobe data if everything went fine.
-func runProbe(cmd *exec.Cmd) (data *ProbeData, err error) {
-	var outputBuf bytes.Buffer
-	var stdErr bytes.Buffer
+// urlArgs validates the URL and returns the arguments for probing it, ending with the URL itself
+func (p *Prober) urlArgs(ctx context.Context, fileURL string) ([]string, error) {
+	var args []string
 
-	cmd.Stdout = &outputBuf
-	cmd.Stderr = &stdErr
+	if p.HTTPOptions != nil && isHTTPURL(fileURL) {
+		httpArgs, err := p.HTTPOptions.Args()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, httpArgs...)
+	}
 
-	err = cmd.Run()
+	if p.URLPolicy != nil {
+		err := p.URLPolicy.validateURL(ctx, fileURL, p.HTTPOptions)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, p.URLPolicy.urlArgs(fileURL)...)
+	}
+
+	// Add the file argument
+	return append(args, fileURL), nil
+}
+
+// cached returns a copy of the cached data for the key when available. Otherwise it probes and caches the result.
+func (p *Prober) cached(key string, probe func() (*ProbeData, error)) (*ProbeData, error) {
+	if data, ok := p.Cache.Get(key); ok {
+		return data.Clone(), nil
+	}
+
+	data, err := probe()
 	if err != nil {
-		return nil, fmt.Errorf("error running %s [%s] %w", binPath, stdErr.String(), err)
+		return data, err
+	}
+	p.Cache.Set(key, data.Clone())
+	return data, nil
+}
+
+// probeArgs returns the ffprobe arguments used for every probe
+func (p *Prober) probeArgs() []string {
+	return []string{
+		"-loglevel", p.logLevel(),
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
 	}
+}
+
+// logLevel returns the log level to run ffprobe at
+func (p *Prober) logLevel() string {
+	if p.LogLevel == "" {
+		if p.Retry != nil {
+			// Errors are needed to determine whether a failure is transient
+			return string(LogLevelError)
+		}
+		return string(LogLevelFatal)
+	}
+
+	level := p.LogLevel
+	if p.Retry != nil && (level == LogLevelQuiet || level == LogLevelPanic || level == LogLevelFatal) {
+		level = LogLevelError
+	}
+	// Prefix every line with its level
+	return "level+" + string(level)
+}
+
+// RunError is returned when ffprobe could not be run or exited with an error. When ffprobe hit one of its
+// ResourceLimits, Err wraps ErrMemoryLimit, ErrCPULimit or ErrOpenFilesLimit.
+type RunError struct {
+	// Path is the path of the ffprobe binary
+	Path string
+	// Stderr is the output ffprobe wrote to stderr
+	Stderr string
+	// ExitCode is the exit code of ffprobe, or -1 when it did not exit normally
+	ExitCode int
+	// Err is the underlying error
+	Err error
+}
+
+func newRunError(stdErr string, err error) *RunError {
+	exitCode := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+	return &RunError{
+		Path:     binPath,
+		Stderr:   stdErr,
+		ExitCode: exitCode,
+		Err:      err,
+	}
+}
+
+func (e *RunError) Error() string {
+	return fmt.Sprintf("error running %s [%s] %v", e.Path, e.Stderr, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *RunError) Unwrap() error {
+	return e.Err
+}
+
+// runProbe executes ffprobe with the given arguments, returning the ffprobe data if everything went fine.
+// When input is not nil, it is copied to the stdin of the command.
+func (p *Prober) runProbe(ctx context.Context, args []string, input io.Reader) (data *ProbeData, err error) {
+	outputBuf := &limitedBuffer{limit: p.MaxOutputSize}
+
+	err = p.execute(ctx, args, input, outputBuf, func(ex *execution) error {
+		data = &ProbeData{}
+		err := json.Unmarshal(outputBuf.Bytes(), data)
+		if err != nil {
+			return fmt.Errorf("error parsing ffprobe output: %w", err)
+		}
+
+		if data.Format == nil {
+			return ErrNoFormat
+		}
+
+		data.setDeprecatedTags()
+
+		if p.LogLevel != "" {
+			data.Warnings = parseLog(ex.stdErr.String())
+		}
+
+		if p.CollectInfo {
+			data.Info = &ProbeInfo{
+				Args:      ex.args,
+				StartTime: ex.start,
+				Duration:  time.Since(ex.start),
+				InputKind: inputKind(args, input),
+			}
+			if ex.copier != nil {
+				data.Info.BytesRead = ex.copier.bytesRead()
+			}
+			// The version is cached, so this only runs ffprobe again the first time
+			data.Info.FFProbeVersion, _ = ffprobeVersion(ctx)
+		}
+		return nil
+	})
+	return data, err
+}
+
+// execution is a single run of ffprobe
+type execution struct {
+	cmd *exec.Cmd
+	// args is the ffprobe command line with secrets redacted, which differs from the arguments of cmd when resource
+	// limits are applied
+	args   []string
+	start  time.Time
+	stdout *countingWriter
+	stdErr *limitedBuffer
+	copier *inputCopier
+}
+
+// countingWriter counts the bytes written to it, and remembers the first error the underlying writer returned
+type countingWriter struct {
+	w   io.Writer
+	n   int
+	err error
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += n
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	return n, err
+}
 
-	data = &ProbeData{}
-	err = json.Unmarshal(outputBuf.Bytes(), data)
+// execute executes ffprobe with the given arguments, writing its output to stdout. When input is not nil, it is
+// copied to the stdin of the command. The process function is called once ffprobe exited successfully, to process
+// its output. Any error stdout returns stops ffprobe, and is returned.
+func (p *Prober) execute(ctx context.Context, args []string, input io.Reader, stdout io.Writer, process func(ex *execution) error) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if p.ResourceLimits != nil && !resourceLimitsSupported {
+		return ErrResourceLimitsUnsupported
+	}
+
+	ex := &execution{
+		cmd:    exec.Command(binPath, args...), //nolint:gosec
+		args:   redactArgs(append([]string{binPath}, args...)),
+		stdout: &countingWriter{w: stdout},
+		stdErr: &limitedBuffer{limit: p.MaxOutputSize},
+	}
+	if p.ResourceLimits != nil {
+		ex.cmd = limitedCommand(p.ResourceLimits, binPath, args)
+	}
+	cmd := ex.cmd
+	cmd.SysProcAttr = procAttributes()
+	cmd.Stdout = ex.stdout
+	cmd.Stderr = ex.stdErr
+
+	hookCtx := p.beforeStart(ctx, ex.args)
+	ex.start = time.Now()
+	defer func() {
+		p.afterFinish(hookCtx, &FinishEvent{
+			Args:       ex.args,
+			Duration:   time.Since(ex.start),
+			ExitCode:   exitCode(cmd),
+			StdoutSize: ex.stdout.n,
+			StderrSize: ex.stdErr.Len(),
+			Err:        err,
+			ErrorKind:  errorKind(ctx, cmd, err),
+		})
+	}()
+
+	if input != nil {
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return fmt.Errorf("error creating stdin pipe: %w", err)
+		}
+		ex.copier = newInputCopier(input, stdin)
+	}
+
+	err = cmd.Start()
 	if err != nil {
-		return data, fmt.Errorf("error parsing ffprobe output: %w", err)
+		return newRunError(ex.stdErr.String(), err)
 	}
 
-	if data.Format == nil {
-		return data, fmt.Errorf("no format data found in ffprobe output")
+	stopWatching := p.watchContext(ctx, cmd.Process)
+	if ex.copier != nil {
+		go ex.copier.run()
 	}
 
-	// Populate the old Tags structs for backwards compatibility purposes:
-	if len(data.Format.TagList) > 0 {
-		data.Format.Tags = &FormatTags{}
-		data.Format.Tags.setFrom(data.Format.TagList)
+	err = cmd.Wait()
+	signalled := stopWatching()
+	if ex.copier != nil {
+		// Wait has closed the stdin pipe by now, stop copying and wait for pending writes to fail
+		ex.copier.stop()
+	}
+	if signalled {
+		// ffprobe may have exited cleanly on the termination signal, its partial output must not be parsed
+		return fmt.Errorf("error running %s [%s]: %w", binPath, ex.stdErr.String(), ctx.Err())
+	}
+	if ex.copier != nil {
+		if copyErr := ex.copier.err(); copyErr != nil {
+			return copyErr
+		}
 	}
-	for _, str := range data.Streams {
-		str.Tags.setFrom(str.TagList)
+	if ex.stdErr.exceeded {
+		return fmt.Errorf("error running %s [%s]: %w", binPath, ex.stdErr.String(), ErrOutputLimit)
+	}
+	if ex.stdout.err != nil {
+		return fmt.Errorf("error running %s [%s]: %w", binPath, ex.stdErr.String(), ex.stdout.err)
+	}
+	if err != nil {
+		if p.ResourceLimits != nil {
+			if limitErr := resourceLimitError(p.ResourceLimits, err, ex.stdErr.String()); limitErr != nil {
+				runErr := newRunError(ex.stdErr.String(), err)
+				runErr.Err = fmt.Errorf("%w (%v)", limitErr, err)
+				return runErr
+			}
+		}
+		return newRunError(ex.stdErr.String(), err)
 	}
 
-	return data, nil
+	return process(ex)
+}
+
+// setDeprecatedTags populates the old Tags structs for backwards compatibility purposes
+func (p *ProbeData) setDeprecatedTags() {
+	if p.Format != nil && len(p.Format.TagList) > 0 {
+		p.Format.Tags = &FormatTags{}
+		p.Format.Tags.setFrom(p.Format.TagList)
+	}
+	for _, str := range p.Streams {
+		if str != nil {
+			str.Tags.setFrom(str.TagList)
+		}
+	}
+}
+
+// watchContext kills the given process and the processes it spawned once the context is done. The returned function
+// stops watching, it must be called once the process has exited. It reports whether the process was signalled.
+func (p *Prober) watchContext(ctx context.Context, proc *os.Process) (stop func() (signalled bool)) {
+	exited := make(chan struct{})
+	watcherDone := make(chan struct{})
+	var signalled bool
+
+	go func() {
+		defer close(watcherDone)
+
+		select {
+		case <-exited:
+			return
+		case <-ctx.Done():
+		}
+		signalled = true
+
+		if p.KillGracePeriod > 0 {
+			_ = terminateProcess(proc)
+
+			timer := time.NewTimer(p.KillGracePeriod)
+			defer timer.Stop()
+
+			select {
+			case <-exited:
+				// ffprobe itself is gone, but the processes it spawned may not be
+			case <-timer.C:
+			}
+		}
+		_ = killProcess(proc)
+	}()
+
+	return func() bool {
+		close(exited)
+		<-watcherDone
+		return signalled
+	}
 }
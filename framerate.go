@@ -0,0 +1,120 @@
+package ffprobe
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidFrameRate is returned when a frame rate cannot be parsed, or is unknown like "0/0"
+var ErrInvalidFrameRate = errors.New("invalid frame rate")
+
+// StandardFrameRate is a frame rate in common use for film, broadcast or phone cameras
+type StandardFrameRate struct {
+	// Num and Den are the exact frame rate as a rational, like 24000/1001
+	Num int
+	Den int
+	// Label is the conventional name of the frame rate, like "23.976"
+	Label string
+	// DropFrame is true when the frame rate can use SMPTE drop-frame timecode
+	DropFrame bool
+}
+
+// Rate returns the frame rate in frames per second
+func (r StandardFrameRate) Rate() float64 {
+	return float64(r.Num) / float64(r.Den)
+}
+
+// standardFrameRates are the standard frame rates, in increasing order
+var standardFrameRates = []StandardFrameRate{
+	{Num: 12, Den: 1, Label: "12"},
+	{Num: 15, Den: 1, Label: "15"},
+	{Num: 24000, Den: 1001, Label: "23.976"},
+	{Num: 24, Den: 1, Label: "24"},
+	{Num: 25, Den: 1, Label: "25"},
+	{Num: 30000, Den: 1001, Label: "29.97", DropFrame: true},
+	{Num: 30, Den: 1, Label: "30"},
+	{Num: 48000, Den: 1001, Label: "47.952"},
+	{Num: 48, Den: 1, Label: "48"},
+	{Num: 50, Den: 1, Label: "50"},
+	{Num: 60000, Den: 1001, Label: "59.94", DropFrame: true},
+	{Num: 60, Den: 1, Label: "60"},
+	{Num: 90, Den: 1, Label: "90"},
+	{Num: 100, Den: 1, Label: "100"},
+	{Num: 120000, Den: 1001, Label: "119.88"},
+	{Num: 120, Den: 1, Label: "120"},
+	{Num: 144, Den: 1, Label: "144"},
+	{Num: 240, Den: 1, Label: "240"},
+}
+
+// frameRateSnapTolerance is the maximum relative distance of a measured frame rate to a standard frame rate to snap to
+// it. Rates like 29.97 and 30 are 0.1% apart, the nearest of the two wins.
+const frameRateSnapTolerance = 0.001
+
+// FrameRateClass is the classification of a frame rate against the standard frame rates
+type FrameRateClass struct {
+	// Rate is the classified frame rate in frames per second
+	Rate float64
+	// Nearest is the standard frame rate nearest to the rate
+	Nearest StandardFrameRate
+	// Distance is the relative distance of the rate to the nearest standard frame rate
+	Distance float64
+	// Standard is true when the rate is close enough to the nearest standard frame rate to be considered equal
+	Standard bool
+	// DropFrame is true when the rate is a standard frame rate that can use SMPTE drop-frame timecode
+	DropFrame bool
+}
+
+// ClassifyFrameRate classifies a frame rate in frames per second against the standard frame rates
+func ClassifyFrameRate(rate float64) FrameRateClass {
+	class := FrameRateClass{Rate: rate, Distance: math.Inf(1)}
+	for _, standard := range standardFrameRates {
+		if d := math.Abs(rate-standard.Rate()) / standard.Rate(); d < class.Distance {
+			class.Nearest, class.Distance = standard, d
+		}
+	}
+	class.Standard = class.Distance <= frameRateSnapTolerance
+	class.DropFrame = class.Standard && class.Nearest.DropFrame
+	return class
+}
+
+// ParseFrameRate parses a frame rate as printed by ffprobe, like "2997/125" or "25", into frames per second
+func ParseFrameRate(frameRate string) (float64, error) {
+	num, den := frameRate, "1"
+	if i := strings.IndexByte(frameRate, '/'); i >= 0 {
+		num, den = frameRate[:i], frameRate[i+1:]
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, ErrInvalidFrameRate
+	}
+	d, err := strconv.ParseFloat(den, 64)
+	if err != nil || n <= 0 || d <= 0 {
+		return 0, ErrInvalidFrameRate
+	}
+	return n / d, nil
+}
+
+// FrameRateClass classifies the average frame rate of the stream against the standard frame rates, falling back to
+// the real base frame rate when the average is unknown
+func (s *Stream) FrameRateClass() (FrameRateClass, error) {
+	rate, err := ParseFrameRate(s.AvgFrameRate)
+	if err != nil {
+		rate, err = ParseFrameRate(s.RFrameRate)
+		if err != nil {
+			return FrameRateClass{}, err
+		}
+	}
+	return ClassifyFrameRate(rate), nil
+}
+
+// snapFrameRate returns the standard frame rate nearest to the measured rate, or the measured rate itself when no
+// standard frame rate is close enough
+func snapFrameRate(rate float64) float64 {
+	class := ClassifyFrameRate(rate)
+	if !class.Standard {
+		return rate
+	}
+	return class.Nearest.Rate()
+}
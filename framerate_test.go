@@ -0,0 +1,39 @@
+package ffprobe
+
+import "testing"
+
+func Test_FrameRateClass(t *testing.T) {
+	tests := []struct {
+		avgFrameRate string
+		rFrameRate   string
+		label        string
+		standard     bool
+		dropFrame    bool
+	}{
+		{avgFrameRate: "24000/1001", label: "23.976", standard: true},
+		{avgFrameRate: "2997/125", label: "23.976", standard: true},
+		{avgFrameRate: "25/1", label: "25", standard: true},
+		{avgFrameRate: "30000/1001", label: "29.97", standard: true, dropFrame: true},
+		{avgFrameRate: "30/1", label: "30", standard: true},
+		{avgFrameRate: "60000/1001", label: "59.94", standard: true, dropFrame: true},
+		{avgFrameRate: "0/0", rFrameRate: "50/1", label: "50", standard: true},
+		{avgFrameRate: "27/1", label: "25", standard: false},
+	}
+	for _, test := range tests {
+		s := Stream{AvgFrameRate: test.avgFrameRate, RFrameRate: test.rFrameRate}
+		class, err := s.FrameRateClass()
+		if err != nil {
+			t.Errorf("Error classifying %s: %v", test.avgFrameRate, err)
+			continue
+		}
+		if class.Nearest.Label != test.label || class.Standard != test.standard || class.DropFrame != test.dropFrame {
+			t.Errorf("Expected %s to classify as %s (standard %v, drop-frame %v), got %+v",
+				test.avgFrameRate, test.label, test.standard, test.dropFrame, class)
+		}
+	}
+
+	s := Stream{AvgFrameRate: "0/0", RFrameRate: "0/0"}
+	if _, err := s.FrameRateClass(); err != ErrInvalidFrameRate {
+		t.Errorf("Expected ErrInvalidFrameRate, got %v", err)
+	}
+}
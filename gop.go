@@ -0,0 +1,259 @@
+package ffprobe
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GOP is a single group of pictures, starting at a keyframe
+type GOP struct {
+	// Start is the presentation timestamp of the keyframe starting the GOP
+	Start time.Duration
+	// Duration is the time until the next keyframe, or until the end of the stream for the last GOP
+	Duration time.Duration
+	// Frames is the amount of frames in the GOP
+	Frames int
+	// Closed is false when frames of the GOP are displayed before its keyframe, so they reference the previous GOP
+	Closed bool
+}
+
+// FrameTypeCounts are the amounts of frames of every picture type
+type FrameTypeCounts struct {
+	I     int
+	P     int
+	B     int
+	Other int
+}
+
+// Total returns the total amount of frames
+func (c FrameTypeCounts) Total() int {
+	return c.I + c.P + c.B + c.Other
+}
+
+// Ratios returns the fraction of I, P and B frames of the total amount of frames
+func (c FrameTypeCounts) Ratios() (i, p, b float64) {
+	total := float64(c.Total())
+	if total == 0 {
+		return 0, 0, 0
+	}
+	return float64(c.I) / total, float64(c.P) / total, float64(c.B) / total
+}
+
+// GOPReport describes the GOP structure of a video stream
+type GOPReport struct {
+	StreamIndex int
+	// GOPCount is the amount of GOPs in the stream
+	GOPCount int
+	// MinDuration, MaxDuration and MeanDuration are the statistics of the GOP durations. The last GOP is left out when
+	// there are more GOPs, as it is usually cut short by the end of the stream.
+	MinDuration  time.Duration
+	MaxDuration  time.Duration
+	MeanDuration time.Duration
+	// Lengths is the distribution of the GOP lengths, mapping the amount of frames to the amount of GOPs
+	Lengths map[int]int
+	// ClosedGOPs and OpenGOPs are the amounts of closed and open GOPs
+	ClosedGOPs int
+	OpenGOPs   int
+	// BPyramidDepth is the maximum amount of positions a frame is decoded ahead of where it is displayed. It is 0
+	// without B-frames, 1 for plain B-frames and higher for hierarchical B-frames.
+	BPyramidDepth int
+	// IrregularGOPs are the GOPs whose length differs from the most common length, leaving out the last GOP
+	IrregularGOPs []GOP
+	// FrameTypes are the amounts of frames of every picture type
+	FrameTypes FrameTypeCounts
+}
+
+// AnalyzeGOPs returns a GOPReport for every video stream. The packets are used to find the GOPs, and the frames to
+// determine the picture types, so all video frames are decoded. This can take a while for long files.
+func AnalyzeGOPs(ctx context.Context, fileURL string) ([]GOPReport, error) {
+	return defaultProber.AnalyzeGOPs(ctx, fileURL)
+}
+
+// AnalyzeGOPs returns a GOPReport for every video stream, using the configuration of the Prober.
+// See the package level AnalyzeGOPs function for more details.
+func (p *Prober) AnalyzeGOPs(ctx context.Context, fileURL string) ([]GOPReport, error) {
+	args := []string{
+		"-select_streams", "v",
+		"-show_entries", "packet=stream_index,pts_time,dts_time,duration_time,flags:frame=stream_index,pict_type",
+	}
+
+	analyzers := make(map[int]*gopAnalyzer)
+	err := p.streamEntries(ctx, fileURL, args, func(e entries) error {
+		index, ok := e.int("stream_index")
+		if !ok {
+			return nil
+		}
+		analyzer := analyzers[int(index)]
+		if analyzer == nil {
+			analyzer = &gopAnalyzer{}
+			analyzers[int(index)] = analyzer
+		}
+
+		switch e[sectionKey] {
+		case "packet":
+			analyzer.addPacket(e)
+		case "frame":
+			analyzer.addFrame(e["pict_type"])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]GOPReport, 0, len(analyzers))
+	for index, analyzer := range analyzers {
+		report := analyzer.report()
+		report.StreamIndex = index
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].StreamIndex < reports[j].StreamIndex
+	})
+	return reports, nil
+}
+
+// gopAnalyzer collects the GOPs of a single stream
+type gopAnalyzer struct {
+	gops       []GOP
+	frameTypes FrameTypeCounts
+	maxDepth   int
+
+	// The GOP currently being read, with the timestamps of its packets in decoding order
+	started bool
+	current GOP
+	pts     []time.Duration
+	end     time.Duration
+}
+
+func (a *gopAnalyzer) addPacket(e entries) {
+	pts, ok := e.duration("pts_time")
+	if !ok {
+		pts, ok = e.duration("dts_time")
+		if !ok {
+			return
+		}
+	}
+
+	if strings.HasPrefix(e["flags"], "K") {
+		if a.started {
+			a.finishGOP(pts)
+		}
+		a.started = true
+		a.current = GOP{Start: pts}
+		a.pts = a.pts[:0]
+	}
+	if !a.started {
+		// Packets before the first keyframe cannot be decoded
+		return
+	}
+
+	a.pts = append(a.pts, pts)
+	duration, _ := e.duration("duration_time")
+	if pts+duration > a.end {
+		a.end = pts + duration
+	}
+}
+
+func (a *gopAnalyzer) addFrame(pictType string) {
+	switch pictType {
+	case "I":
+		a.frameTypes.I++
+	case "P":
+		a.frameTypes.P++
+	case "B":
+		a.frameTypes.B++
+	default:
+		a.frameTypes.Other++
+	}
+}
+
+// finishGOP completes the current GOP, which ends at the given time
+func (a *gopAnalyzer) finishGOP(end time.Duration) {
+	gop := a.current
+	gop.Duration = end - gop.Start
+	gop.Frames = len(a.pts)
+	gop.Closed = true
+
+	display := make([]time.Duration, len(a.pts))
+	copy(display, a.pts)
+	sort.Slice(display, func(i, j int) bool {
+		return display[i] < display[j]
+	})
+
+	for decodeIdx, pts := range a.pts {
+		if pts < gop.Start {
+			gop.Closed = false
+		}
+		displayIdx := sort.Search(len(display), func(i int) bool {
+			return display[i] >= pts
+		})
+		if depth := decodeIdx - displayIdx; depth > a.maxDepth {
+			a.maxDepth = depth
+		}
+	}
+
+	a.gops = append(a.gops, gop)
+}
+
+func (a *gopAnalyzer) report() GOPReport {
+	if a.started {
+		a.finishGOP(a.end)
+		a.started = false
+	}
+
+	report := GOPReport{
+		GOPCount:      len(a.gops),
+		Lengths:       make(map[int]int),
+		BPyramidDepth: a.maxDepth,
+		FrameTypes:    a.frameTypes,
+	}
+	if len(a.gops) == 0 {
+		return report
+	}
+
+	for _, gop := range a.gops {
+		report.Lengths[gop.Frames]++
+		if gop.Closed {
+			report.ClosedGOPs++
+		} else {
+			report.OpenGOPs++
+		}
+	}
+
+	// Leave out the last GOP for the statistics, it is usually cut short by the end of the stream
+	regular := a.gops
+	if len(regular) > 1 {
+		regular = regular[:len(regular)-1]
+	}
+
+	var total time.Duration
+	lengths := make(map[int]int)
+	report.MinDuration = regular[0].Duration
+	for _, gop := range regular {
+		total += gop.Duration
+		if gop.Duration < report.MinDuration {
+			report.MinDuration = gop.Duration
+		}
+		if gop.Duration > report.MaxDuration {
+			report.MaxDuration = gop.Duration
+		}
+		lengths[gop.Frames]++
+	}
+	report.MeanDuration = total / time.Duration(len(regular))
+
+	common, commonCount := 0, 0
+	for length, count := range lengths {
+		if count > commonCount || (count == commonCount && length > common) {
+			common, commonCount = length, count
+		}
+	}
+	for _, gop := range regular {
+		if gop.Frames != common {
+			report.IrregularGOPs = append(report.IrregularGOPs, gop)
+		}
+	}
+	return report
+}
@@ -0,0 +1,120 @@
+//go:build !windows
+// +build !windows
+
+package ffprobe
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const fakeGOPOutput = `packet|stream_index=0|pts_time=-0.040000|dts_time=N/A|duration_time=0.040000|flags=__
+packet|stream_index=0|pts_time=0.000000|dts_time=N/A|duration_time=0.040000|flags=K_
+packet|stream_index=0|pts_time=0.120000|dts_time=N/A|duration_time=0.040000|flags=__
+packet|stream_index=0|pts_time=0.040000|dts_time=N/A|duration_time=0.040000|flags=__
+packet|stream_index=0|pts_time=0.080000|dts_time=N/A|duration_time=0.040000|flags=__
+packet|stream_index=0|pts_time=0.160000|dts_time=N/A|duration_time=0.040000|flags=K_
+packet|stream_index=0|pts_time=0.280000|dts_time=N/A|duration_time=0.040000|flags=__
+packet|stream_index=0|pts_time=0.200000|dts_time=N/A|duration_time=0.040000|flags=__
+packet|stream_index=0|pts_time=0.240000|dts_time=N/A|duration_time=0.040000|flags=__
+packet|stream_index=0|pts_time=0.360000|dts_time=N/A|duration_time=0.040000|flags=K_
+packet|stream_index=0|pts_time=0.320000|dts_time=N/A|duration_time=0.040000|flags=__
+packet|stream_index=0|pts_time=0.440000|dts_time=N/A|duration_time=0.040000|flags=__
+packet|stream_index=0|pts_time=0.400000|dts_time=N/A|duration_time=0.040000|flags=__
+packet|stream_index=0|pts_time=0.480000|dts_time=N/A|duration_time=0.040000|flags=K_
+packet|stream_index=0|pts_time=0.520000|dts_time=N/A|duration_time=0.040000|flags=__
+packet|stream_index=0|pts_time=0.560000|dts_time=N/A|duration_time=0.040000|flags=K_
+packet|stream_index=0|pts_time=0.600000|dts_time=N/A|duration_time=0.040000|flags=__
+packet|stream_index=0|pts_time=0.640000|dts_time=N/A|duration_time=0.040000|flags=__
+packet|stream_index=0|pts_time=0.680000|dts_time=N/A|duration_time=0.040000|flags=__
+frame|stream_index=0|pict_type=I
+frame|stream_index=0|pict_type=B
+frame|stream_index=0|pict_type=B
+frame|stream_index=0|pict_type=P
+frame|stream_index=0|pict_type=I
+frame|stream_index=0|pict_type=B
+frame|stream_index=0|pict_type=B
+frame|stream_index=0|pict_type=P
+frame|stream_index=0|pict_type=I
+frame|stream_index=0|pict_type=B
+frame|stream_index=0|pict_type=B
+frame|stream_index=0|pict_type=P
+frame|stream_index=0|pict_type=I
+frame|stream_index=0|pict_type=P
+frame|stream_index=0|pict_type=I
+frame|stream_index=0|pict_type=P
+frame|stream_index=0|pict_type=P
+frame|stream_index=0|pict_type=P
+packet|stream_index=2|pts_time=0.000000|dts_time=N/A|duration_time=0.040000|flags=K_
+packet|stream_index=2|pts_time=0.040000|dts_time=N/A|duration_time=0.040000|flags=__
+frame|stream_index=2|pict_type=I
+frame|stream_index=2|pict_type=P`
+
+func Test_AnalyzeGOPs(t *testing.T) {
+	defer useFakeFFProbe(t, "cat <<'EOF'\n"+fakeGOPOutput+"\nEOF")()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	reports, err := AnalyzeGOPs(ctx, testPath)
+	if err != nil {
+		t.Fatalf("Error analyzing GOPs: %v", err)
+	}
+	if len(reports) != 2 || reports[0].StreamIndex != 0 || reports[1].StreamIndex != 2 {
+		t.Fatalf("Expected reports for streams 0 and 2, got %+v", reports)
+	}
+
+	const ms = time.Millisecond
+	expected := GOPReport{
+		StreamIndex:   0,
+		GOPCount:      5,
+		MinDuration:   80 * ms,
+		MaxDuration:   200 * ms,
+		MeanDuration:  140 * ms,
+		Lengths:       map[int]int{4: 4, 2: 1},
+		ClosedGOPs:    4,
+		OpenGOPs:      1,
+		BPyramidDepth: 1,
+		IrregularGOPs: []GOP{{Start: 480 * ms, Duration: 80 * ms, Frames: 2, Closed: true}},
+		FrameTypes:    FrameTypeCounts{I: 5, P: 7, B: 6},
+	}
+	if !reflect.DeepEqual(reports[0], expected) {
+		t.Errorf("Unexpected report:\n%+v\nexpected:\n%+v", reports[0], expected)
+	}
+
+	i, p, b := reports[0].FrameTypes.Ratios()
+	if i+p+b != 1 || b != 6.0/18 {
+		t.Errorf("Unexpected ratios %v, %v, %v", i, p, b)
+	}
+
+	if reports[1].GOPCount != 1 || reports[1].MeanDuration != 80*ms || reports[1].BPyramidDepth != 0 {
+		t.Errorf("Unexpected report for the second stream: %+v", reports[1])
+	}
+}
+
+func Test_gopAnalyzer_Pyramid(t *testing.T) {
+	// I0 P8 B4 b2 b1 b3 b6 b5 b7: a hierarchical GOP of 7 B-frames
+	analyzer := &gopAnalyzer{}
+	for i, frame := range []int{0, 8, 4, 2, 1, 3, 6, 5, 7} {
+		flags := "__"
+		if i == 0 {
+			flags = "K_"
+		}
+		analyzer.addPacket(entries{
+			"pts_time":      strconv.FormatFloat(float64(frame)*0.04, 'f', 6, 64),
+			"duration_time": "0.040000",
+			"flags":         flags,
+		})
+	}
+
+	report := analyzer.report()
+	if report.BPyramidDepth != 3 {
+		t.Errorf("Expected a pyramid depth of 3, got %d", report.BPyramidDepth)
+	}
+	if report.MeanDuration != 360*time.Millisecond {
+		t.Errorf("Unexpected GOP duration %v", report.MeanDuration)
+	}
+}
@@ -0,0 +1,147 @@
+package ffprobe
+
+import (
+	"strconv"
+	"strings"
+)
+
+// HDRFormat is the high dynamic range format of a video stream
+type HDRFormat string
+
+const (
+	// HDRFormatSDR is standard dynamic range
+	HDRFormatSDR HDRFormat = "sdr"
+	// HDRFormatHDR10 is the PQ transfer function with static metadata
+	HDRFormatHDR10 HDRFormat = "hdr10"
+	// HDRFormatHDR10Plus is HDR10 with SMPTE ST 2094-40 dynamic metadata
+	HDRFormatHDR10Plus HDRFormat = "hdr10+"
+	// HDRFormatHLG is the hybrid log-gamma transfer function
+	HDRFormatHLG HDRFormat = "hlg"
+	// HDRFormatDolbyVision is Dolby Vision
+	HDRFormatDolbyVision HDRFormat = "dolby_vision"
+)
+
+// HDRInfo is the high dynamic range classification of a video stream
+type HDRInfo struct {
+	Format HDRFormat
+	// DolbyVisionProfile is the Dolby Vision profile, or 0 when the stream is not Dolby Vision or the profile is unknown
+	DolbyVisionProfile int
+	// Issues describes metadata that is missing or inconsistent with the format
+	Issues []string
+}
+
+// dolbyVisionCodecTags are the codec tags of Dolby Vision streams in MP4
+var dolbyVisionCodecTags = []string{"dvh1", "dvhe", "dva1", "dvav", "dav1"}
+
+// HDRFormat classifies the high dynamic range format of the stream from its color properties and side data
+func (s *Stream) HDRFormat() HDRInfo {
+	var info HDRInfo
+
+	_, masteringErr := s.SideDataList.GetMasteringDisplayMetadata()
+	_, lightLevelErr := s.SideDataList.GetContentLightLevel()
+	// Streams use the packet side data name, but accept the frame side data name as well
+	_, hdr10PlusErr := s.SideDataList.FindSideData(SideDataTypeHDR10Plus)
+	if hdr10PlusErr != nil {
+		_, hdr10PlusErr = s.SideDataList.FindSideData(SideDataTypeHDR10PlusFrame)
+	}
+	hasMastering := masteringErr == nil
+	hasLightLevel := lightLevelErr == nil
+
+	switch {
+	case s.isDolbyVision():
+		info.Format = HDRFormatDolbyVision
+		info.DolbyVisionProfile = s.dolbyVisionProfile()
+		if info.DolbyVisionProfile == 0 {
+			info.Issues = append(info.Issues, "Dolby Vision configuration record is missing")
+		}
+	case s.ColorTransfer == "smpte2084" && hdr10PlusErr == nil:
+		info.Format = HDRFormatHDR10Plus
+	case s.ColorTransfer == "smpte2084":
+		info.Format = HDRFormatHDR10
+	case s.ColorTransfer == "arib-std-b67":
+		info.Format = HDRFormatHLG
+	default:
+		info.Format = HDRFormatSDR
+	}
+
+	switch info.Format {
+	case HDRFormatHDR10, HDRFormatHDR10Plus:
+		if !hasMastering {
+			info.Issues = append(info.Issues, "mastering display metadata is missing")
+		}
+		if !hasLightLevel {
+			info.Issues = append(info.Issues, "content light level metadata is missing")
+		}
+		fallthrough
+	case HDRFormatHLG:
+		if s.ColorPrimaries != "bt2020" {
+			info.Issues = append(info.Issues, "color primaries are "+describeColorValue(s.ColorPrimaries)+" instead of bt2020")
+		}
+		if depth := pixFmtBitDepth(s.PixFmt); depth > 0 && depth < 10 {
+			info.Issues = append(info.Issues, "pixel format "+s.PixFmt+" has less than 10 bits per component")
+		}
+	case HDRFormatSDR:
+		if hasMastering || hdr10PlusErr == nil {
+			info.Issues = append(info.Issues, "HDR metadata is present but the color transfer is "+
+				describeColorValue(s.ColorTransfer))
+		} else if s.ColorPrimaries == "bt2020" && (s.ColorTransfer == "" || s.ColorTransfer == "unknown") {
+			info.Issues = append(info.Issues, "color primaries are bt2020 but the color transfer is unspecified")
+		}
+	}
+	return info
+}
+
+// isDolbyVision returns whether the stream has a Dolby Vision configuration record or codec tag
+func (s *Stream) isDolbyVision() bool {
+	if _, err := s.SideDataList.FindSideData(SideDataTypeDOVIConfig); err == nil {
+		return true
+	}
+	for _, tag := range dolbyVisionCodecTags {
+		if s.CodecTagString == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// dolbyVisionProfile returns the profile from the Dolby Vision configuration record, or 0 when unknown
+func (s *Stream) dolbyVisionProfile() int {
+	config, err := s.SideDataList.GetDOVIConfig()
+	if err != nil {
+		return 0
+	}
+	return config.DVProfile
+}
+
+// pixFmtBitDepth returns the bits per component of a pixel format like "yuv420p10le" or "p010le",
+// or 0 when it cannot be determined
+func pixFmtBitDepth(pixFmt string) int {
+	if pixFmt == "" {
+		return 0
+	}
+	name := strings.TrimSuffix(strings.TrimSuffix(pixFmt, "le"), "be")
+	end := len(name)
+	start := end
+	for start > 0 && name[start-1] >= '0' && name[start-1] <= '9' {
+		start--
+	}
+	if start == end || start == 0 || name[start-1] != 'p' {
+		// Planar formats without a depth suffix, like yuv420p, have 8 bits per component
+		if strings.HasSuffix(name, "p") {
+			return 8
+		}
+		return 0
+	}
+	depth, err := strconv.Atoi(name[start:end])
+	if err != nil {
+		return 0
+	}
+	return depth
+}
+
+func describeColorValue(value string) string {
+	if value == "" {
+		return "unspecified"
+	}
+	return value
+}
@@ -0,0 +1,131 @@
+package ffprobe
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func Test_HDRFormat(t *testing.T) {
+	mastering := SideData{
+		SideDataBase: SideDataBase{Type: SideDataTypeMasteringDisplayMetadata},
+		Data:         &SideDataMasteringDisplayMetadata{},
+	}
+	lightLevel := SideData{
+		SideDataBase: SideDataBase{Type: SideDataTypeContentLightLevel},
+		Data:         &SideDataContentLightLevel{MaxContent: 1000, MaxAverage: 400},
+	}
+	hdr10Plus := SideData{
+		SideDataBase: SideDataBase{Type: SideDataTypeHDR10Plus},
+		Data:         &SideDataUnknown{},
+	}
+	dovi := SideData{
+		SideDataBase: SideDataBase{Type: SideDataTypeDOVIConfig},
+		Data:         &SideDataDOVIConfig{DVProfile: 8, DVBLSignalCompatibilityID: 1},
+	}
+
+	tests := []struct {
+		name   string
+		stream Stream
+		info   HDRInfo
+	}{
+		{
+			name:   "sdr",
+			stream: Stream{PixFmt: "yuv420p", ColorTransfer: "bt709", ColorPrimaries: "bt709"},
+			info:   HDRInfo{Format: HDRFormatSDR},
+		},
+		{
+			name: "hdr10",
+			stream: Stream{PixFmt: "yuv420p10le", ColorTransfer: "smpte2084", ColorPrimaries: "bt2020",
+				SideDataList: SideDataList{mastering, lightLevel}},
+			info: HDRInfo{Format: HDRFormatHDR10},
+		},
+		{
+			name:   "hdr10 without metadata",
+			stream: Stream{PixFmt: "yuv420p", ColorTransfer: "smpte2084", ColorPrimaries: "bt709"},
+			info: HDRInfo{Format: HDRFormatHDR10, Issues: []string{
+				"mastering display metadata is missing",
+				"content light level metadata is missing",
+				"color primaries are bt709 instead of bt2020",
+				"pixel format yuv420p has less than 10 bits per component",
+			}},
+		},
+		{
+			name: "hdr10+",
+			stream: Stream{PixFmt: "yuv420p10le", ColorTransfer: "smpte2084", ColorPrimaries: "bt2020",
+				SideDataList: SideDataList{mastering, lightLevel, hdr10Plus}},
+			info: HDRInfo{Format: HDRFormatHDR10Plus},
+		},
+		{
+			name:   "hlg",
+			stream: Stream{PixFmt: "p010le", ColorTransfer: "arib-std-b67", ColorPrimaries: "bt2020"},
+			info:   HDRInfo{Format: HDRFormatHLG},
+		},
+		{
+			name: "dolby vision",
+			stream: Stream{PixFmt: "yuv420p10le", ColorTransfer: "smpte2084", ColorPrimaries: "bt2020",
+				SideDataList: SideDataList{dovi}},
+			info: HDRInfo{Format: HDRFormatDolbyVision, DolbyVisionProfile: 8},
+		},
+		{
+			name:   "dolby vision without configuration record",
+			stream: Stream{PixFmt: "yuv420p10le", CodecTagString: "dvh1"},
+			info: HDRInfo{Format: HDRFormatDolbyVision, Issues: []string{
+				"Dolby Vision configuration record is missing",
+			}},
+		},
+		{
+			name:   "sdr with mastering display",
+			stream: Stream{PixFmt: "yuv420p10le", ColorTransfer: "bt709", SideDataList: SideDataList{mastering}},
+			info: HDRInfo{Format: HDRFormatSDR, Issues: []string{
+				"HDR metadata is present but the color transfer is bt709",
+			}},
+		},
+	}
+	for _, test := range tests {
+		if info := test.stream.HDRFormat(); !reflect.DeepEqual(info, test.info) {
+			t.Errorf("%s: expected %+v, got %+v", test.name, test.info, info)
+		}
+	}
+}
+
+func Test_HDRFormat_ProbeOutput(t *testing.T) {
+	// A stream as printed by ffprobe -show_streams
+	var stream Stream
+	err := json.Unmarshal([]byte(`{
+		"index": 0,
+		"codec_name": "hevc",
+		"pix_fmt": "yuv420p10le",
+		"color_transfer": "smpte2084",
+		"color_primaries": "bt2020",
+		"side_data_list": [
+			{
+				"side_data_type": "Mastering display metadata",
+				"red_x": "34000/50000",
+				"red_y": "16000/50000",
+				"green_x": "13250/50000",
+				"green_y": "34500/50000",
+				"blue_x": "7500/50000",
+				"blue_y": "3000/50000",
+				"white_point_x": "15635/50000",
+				"white_point_y": "16450/50000",
+				"min_luminance": "50/10000",
+				"max_luminance": "10000000/10000"
+			},
+			{
+				"side_data_type": "Content light level metadata",
+				"max_content": 1000,
+				"max_average": 400
+			},
+			{
+				"side_data_type": "HDR10+ Dynamic Metadata (SMPTE 2094-40)"
+			}
+		]
+	}`), &stream)
+	if err != nil {
+		t.Fatalf("Error unmarshalling stream: %v", err)
+	}
+	if info := stream.HDRFormat(); info.Format != HDRFormatHDR10Plus || len(info.Issues) > 0 {
+		t.Errorf("Expected HDR10+ without issues, got %+v", info)
+	}
+}
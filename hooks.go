@@ -0,0 +1,142 @@
+package ffprobe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"time"
+)
+
+// ErrorKind classifies the way a probe failed
+type ErrorKind string
+
+const (
+	// ErrorKindNone means the probe succeeded
+	ErrorKindNone ErrorKind = ""
+	// ErrorKindStart means ffprobe could not be started
+	ErrorKindStart ErrorKind = "start"
+	// ErrorKindCanceled means ffprobe was killed or not started because the context was done
+	ErrorKindCanceled ErrorKind = "canceled"
+	// ErrorKindExit means ffprobe exited with an error
+	ErrorKindExit ErrorKind = "exit"
+	// ErrorKindInput means reading the input of ProbeReader failed
+	ErrorKindInput ErrorKind = "input"
+	// ErrorKindOutputLimit means ffprobe wrote more output than allowed
+	ErrorKindOutputLimit ErrorKind = "output_limit"
+	// ErrorKindResourceLimit means ffprobe hit one of its resource limits
+	ErrorKindResourceLimit ErrorKind = "resource_limit"
+	// ErrorKindParse means the output of ffprobe could not be parsed
+	ErrorKindParse ErrorKind = "parse"
+)
+
+// Hooks are called around every ffprobe invocation, for example to collect metrics or create tracing spans
+type Hooks struct {
+	// BeforeStart is called right before ffprobe is started. The returned context is passed to AfterFinish, which
+	// allows passing along a tracing span. Returning nil keeps the original context.
+	BeforeStart func(ctx context.Context, event *StartEvent) context.Context
+	// AfterFinish is called once ffprobe has finished and its output is processed
+	AfterFinish func(ctx context.Context, event *FinishEvent)
+}
+
+// StartEvent describes an ffprobe invocation that is about to start
+type StartEvent struct {
+	// Args is the full command line, starting with the path of the ffprobe binary. The values of the headers and
+	// cookies options and passwords in URLs are redacted.
+	Args []string
+}
+
+// FinishEvent describes a finished ffprobe invocation
+type FinishEvent struct {
+	// Args is the full command line, starting with the path of the ffprobe binary. The values of the headers and
+	// cookies options and passwords in URLs are redacted.
+	Args []string
+	// Duration is the time between starting ffprobe and processing its output
+	Duration time.Duration
+	// ExitCode is the exit code of ffprobe, or -1 when it did not exit normally or did not start at all
+	ExitCode int
+	// StdoutSize and StderrSize are the amount of bytes ffprobe wrote to stdout and stderr
+	StdoutSize int
+	StderrSize int
+	// Err is the error the probe failed with, if any
+	Err error
+	// ErrorKind classifies Err
+	ErrorKind ErrorKind
+}
+
+// Logger logs ffprobe invocations. It is implemented by *slog.Logger, any other logger can be adapted to it.
+// The args are alternating keys and values.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+}
+
+// beforeStart runs the before start hook, returning the context for the after finish hook
+func (p *Prober) beforeStart(ctx context.Context, args []string) context.Context {
+	if p.Hooks == nil || p.Hooks.BeforeStart == nil {
+		return ctx
+	}
+	hookCtx := p.Hooks.BeforeStart(ctx, &StartEvent{Args: args})
+	if hookCtx == nil {
+		return ctx
+	}
+	return hookCtx
+}
+
+// afterFinish runs the after finish hook and logs the invocation
+func (p *Prober) afterFinish(ctx context.Context, event *FinishEvent) {
+	if p.Hooks != nil && p.Hooks.AfterFinish != nil {
+		p.Hooks.AfterFinish(ctx, event)
+	}
+
+	if p.Logger == nil {
+		return
+	}
+	attrs := []interface{}{
+		"args", event.Args,
+		"duration", event.Duration,
+		"exit_code", event.ExitCode,
+		"stdout_size", event.StdoutSize,
+		"stderr_size", event.StderrSize,
+	}
+	if event.Err != nil {
+		p.Logger.Warn("ffprobe failed", append(attrs, "error_kind", string(event.ErrorKind), "error", event.Err)...)
+		return
+	}
+	p.Logger.Debug("ffprobe finished", attrs...)
+}
+
+// exitCode returns the exit code of the finished command, or -1
+func exitCode(cmd *exec.Cmd) int {
+	if cmd.ProcessState == nil {
+		return -1
+	}
+	return cmd.ProcessState.ExitCode()
+}
+
+// errorKind classifies the error of a probe
+func errorKind(ctx context.Context, cmd *exec.Cmd, err error) ErrorKind {
+	var inputErr *InputReadError
+	var runErr *RunError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == nil:
+		return ErrorKindNone
+	case ctx.Err() != nil && errors.Is(err, ctx.Err()):
+		// ffprobe may also have failed on its own just before the context was done
+		return ErrorKindCanceled
+	case errors.As(err, &inputErr):
+		return ErrorKindInput
+	case errors.Is(err, ErrOutputLimit):
+		return ErrorKindOutputLimit
+	case errors.Is(err, ErrMemoryLimit), errors.Is(err, ErrCPULimit), errors.Is(err, ErrOpenFilesLimit):
+		return ErrorKindResourceLimit
+	case errors.Is(err, ErrNoFormat), errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return ErrorKindParse
+	case cmd.ProcessState != nil && errors.As(err, &runErr):
+		return ErrorKindExit
+	default:
+		return ErrorKindStart
+	}
+}
@@ -0,0 +1,297 @@
+package ffprobe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidHTTPOption is returned when an HTTP option cannot be passed to ffprobe safely
+var ErrInvalidHTTPOption = errors.New("invalid http option")
+
+// redacted replaces secrets in the command lines passed to hooks, loggers and ProbeInfo
+const redacted = "[REDACTED]"
+
+// secretOptions are the ffprobe options whose values may contain credentials
+var secretOptions = map[string]bool{"-headers": true, "-cookies": true}
+
+// HTTPOptions are the options used when probing HTTP(S) URLs. They are rendered into the corresponding options of the
+// ffmpeg http protocol, see https://ffmpeg.org/ffmpeg-protocols.html#http
+type HTTPOptions struct {
+	// Headers are sent with every request
+	Headers http.Header
+	// Username and Password are sent using basic authentication when Username is not empty
+	Username string
+	Password string
+	// Cookies are sent with every request matching their domain and path
+	Cookies []*http.Cookie
+	// UserAgent overrides the default user agent of ffprobe
+	UserAgent string
+	// ConnectTimeout is the timeout for establishing the connection, and any socket operation after that
+	ConnectTimeout time.Duration
+	// ReadTimeout is the timeout for any read or write operation on the connection
+	ReadTimeout time.Duration
+	// Reconnect enables reconnecting when the connection is lost, when set
+	Reconnect *ReconnectPolicy
+	// TLSVerify enables verification of the TLS certificate of the server, which ffmpeg does not do by default
+	TLSVerify bool
+	// CAFile is the path to a file with the certificate authorities used for TLS verification
+	CAFile string
+}
+
+// ReconnectPolicy configures when ffprobe reconnects to an HTTP server
+type ReconnectPolicy struct {
+	// Streamed also reconnects for streams that are not seekable
+	Streamed bool
+	// OnNetworkError reconnects on network errors, like a reset connection
+	OnNetworkError bool
+	// OnHTTPError is a comma separated list of HTTP status codes to reconnect on, for example "429,5xx"
+	OnHTTPError string
+	// MaxDelay is the maximum delay between reconnection attempts
+	MaxDelay time.Duration
+}
+
+// Args returns the ffprobe arguments for the options. An error wrapping ErrInvalidHTTPOption is returned when a
+// header, cookie or user agent contains characters that would corrupt the HTTP request.
+func (o *HTTPOptions) Args() ([]string, error) {
+	var args []string
+
+	headers, err := o.headers()
+	if err != nil {
+		return nil, err
+	}
+	if headers != "" {
+		args = append(args, "-headers", headers)
+	}
+
+	if o.UserAgent != "" {
+		if err := checkHTTPValue("user agent", o.UserAgent); err != nil {
+			return nil, err
+		}
+		args = append(args, "-user_agent", o.UserAgent)
+	}
+
+	if len(o.Cookies) > 0 {
+		cookies, err := o.cookies()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "-cookies", cookies)
+	}
+
+	if o.ConnectTimeout > 0 {
+		args = append(args, "-timeout", microseconds(o.ConnectTimeout))
+	}
+	if o.ReadTimeout > 0 {
+		args = append(args, "-rw_timeout", microseconds(o.ReadTimeout))
+	}
+
+	if o.Reconnect != nil {
+		args = append(args, o.Reconnect.args()...)
+	}
+
+	if o.TLSVerify {
+		args = append(args, "-tls_verify", "1")
+	}
+	if o.CAFile != "" {
+		args = append(args, "-ca_file", o.CAFile)
+	}
+	return args, nil
+}
+
+// headers returns the headers as a single CRLF separated string, as expected by the headers option
+func (o *HTTPOptions) headers() (string, error) {
+	names := make([]string, 0, len(o.Headers))
+	for name := range o.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf strings.Builder
+	for _, name := range names {
+		if err := checkHTTPValue("header name", name); err != nil {
+			return "", err
+		}
+		if strings.ContainsAny(name, ": ") {
+			return "", fmt.Errorf("%w: header name %q", ErrInvalidHTTPOption, name)
+		}
+		for _, value := range o.Headers[name] {
+			if err := checkHTTPValue("header value", value); err != nil {
+				return "", err
+			}
+			buf.WriteString(http.CanonicalHeaderKey(name) + ": " + value + "\r\n")
+		}
+	}
+
+	if o.Username != "" {
+		credentials := o.Username + ":" + o.Password
+		if err := checkHTTPValue("credentials", credentials); err != nil {
+			return "", err
+		}
+		buf.WriteString("Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte(credentials)) + "\r\n")
+	}
+	return buf.String(), nil
+}
+
+// cookies returns the cookies in Set-Cookie format, separated by newlines, as expected by the cookies option
+func (o *HTTPOptions) cookies() (string, error) {
+	lines := make([]string, 0, len(o.Cookies))
+	for _, cookie := range o.Cookies {
+		c := *cookie
+		if c.Path == "" {
+			// Older ffmpeg versions ignore cookies without path
+			c.Path = "/"
+		}
+		line := c.String()
+		if line == "" {
+			return "", fmt.Errorf("%w: cookie %q", ErrInvalidHTTPOption, cookie.Name)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// client returns an HTTP client connecting like ffprobe does with the options, for requesting the URL before probing
+func (o *HTTPOptions) client(u *url.URL) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Like ffmpeg, only verify the certificate of the server when asked to
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: !o.TLSVerify} //nolint:gosec
+	if o.CAFile != "" {
+		pem, err := ioutil.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA file: %w", err)
+		}
+		transport.TLSClientConfig.RootCAs = x509.NewCertPool()
+		if !transport.TLSClientConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", o.CAFile)
+		}
+	}
+	if o.ConnectTimeout > 0 {
+		transport.DialContext = (&net.Dialer{Timeout: o.ConnectTimeout}).DialContext
+		transport.TLSHandshakeTimeout = o.ConnectTimeout
+	}
+	if o.ReadTimeout > 0 {
+		transport.ResponseHeaderTimeout = o.ReadTimeout
+	}
+
+	client := &http.Client{Transport: transport}
+	if len(o.Cookies) > 0 {
+		// The jar only sends the cookies to the hosts they are meant for, on every redirect
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, err
+		}
+		cookies := make([]*http.Cookie, 0, len(o.Cookies))
+		for _, cookie := range o.Cookies {
+			c := *cookie
+			if c.Path == "" {
+				c.Path = "/"
+			}
+			cookies = append(cookies, &c)
+		}
+		jar.SetCookies(u, cookies)
+		client.Jar = jar
+	}
+	return client, nil
+}
+
+// newRequest returns a request for the URL with the headers, credentials and user agent of the options
+func (o *HTTPOptions) newRequest(ctx context.Context, fileURL string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range o.Headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	if o.Username != "" {
+		req.SetBasicAuth(o.Username, o.Password)
+	}
+	if o.UserAgent != "" {
+		req.Header.Set("User-Agent", o.UserAgent)
+	}
+	return req.WithContext(ctx), nil
+}
+
+func (r *ReconnectPolicy) args() []string {
+	args := []string{"-reconnect", "1"}
+	if r.Streamed {
+		args = append(args, "-reconnect_streamed", "1")
+	}
+	if r.OnNetworkError {
+		args = append(args, "-reconnect_on_network_error", "1")
+	}
+	if r.OnHTTPError != "" {
+		args = append(args, "-reconnect_on_http_error", r.OnHTTPError)
+	}
+	if r.MaxDelay > 0 {
+		args = append(args, "-reconnect_delay_max", strconv.Itoa(int((r.MaxDelay+time.Second-1)/time.Second)))
+	}
+	return args
+}
+
+func checkHTTPValue(kind, value string) error {
+	if strings.ContainsAny(value, "\r\n\x00") {
+		return fmt.Errorf("%w: %s contains a line break", ErrInvalidHTTPOption, kind)
+	}
+	return nil
+}
+
+func microseconds(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Microsecond), 10)
+}
+
+// redactArgs returns a copy of the ffprobe command line with the values of the headers and cookies options and the
+// passwords in URLs replaced, so it can be logged safely
+func redactArgs(args []string) []string {
+	redactedArgs := make([]string, len(args))
+	for i, arg := range args {
+		switch {
+		case i > 0 && secretOptions[args[i-1]]:
+			redactedArgs[i] = redacted
+		case strings.Contains(arg, "://") && strings.Contains(arg, "@"):
+			redactedArgs[i] = redactURL(arg)
+		default:
+			redactedArgs[i] = arg
+		}
+	}
+	return redactedArgs
+}
+
+// redactURL replaces the password in the URL, if any
+func redactURL(fileURL string) string {
+	u, err := url.Parse(fileURL)
+	if err != nil || u.User == nil {
+		return fileURL
+	}
+	if _, ok := u.User.Password(); !ok {
+		return fileURL
+	}
+	// Like URL.Redacted, which requires a newer Go version
+	u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	return u.String()
+}
+
+// isHTTPURL returns whether the URL is probed using the http protocol
+func isHTTPURL(fileURL string) bool {
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return false
+	}
+	scheme := urlScheme(u)
+	return scheme == "http" || scheme == "https"
+}
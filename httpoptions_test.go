@@ -0,0 +1,91 @@
+package ffprobe
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_HTTPOptions_Args(t *testing.T) {
+	opts := &HTTPOptions{
+		Headers:        http.Header{"x-token": {"abc"}, "Accept": {"*/*"}},
+		Username:       "user",
+		Password:       "pass",
+		Cookies:        []*http.Cookie{{Name: "session", Value: "123"}},
+		UserAgent:      "test-agent",
+		ConnectTimeout: 2 * time.Second,
+		ReadTimeout:    500 * time.Millisecond,
+		Reconnect:      &ReconnectPolicy{OnNetworkError: true, MaxDelay: 1500 * time.Millisecond},
+		TLSVerify:      true,
+	}
+
+	args, err := opts.Args()
+	if err != nil {
+		t.Fatalf("Error getting args: %v", err)
+	}
+
+	expected := []string{
+		"-headers", "Accept: */*\r\nX-Token: abc\r\nAuthorization: Basic dXNlcjpwYXNz\r\n",
+		"-user_agent", "test-agent",
+		"-cookies", "session=123; Path=/",
+		"-timeout", "2000000",
+		"-rw_timeout", "500000",
+		"-reconnect", "1", "-reconnect_on_network_error", "1", "-reconnect_delay_max", "2",
+		"-tls_verify", "1",
+	}
+	if strings.Join(args, "|") != strings.Join(expected, "|") {
+		t.Errorf("Unexpected args:\n%q\nexpected:\n%q", args, expected)
+	}
+}
+
+func Test_HTTPOptions_Injection(t *testing.T) {
+	for _, opts := range []*HTTPOptions{
+		{Headers: http.Header{"X-Test": {"a\r\nHost: evil"}}},
+		{Headers: http.Header{"X-Test: a\r\nHost": {"evil"}}},
+		{UserAgent: "agent\nX-Test: a"},
+		{Username: "user\r\n"},
+	} {
+		_, err := opts.Args()
+		if !errors.Is(err, ErrInvalidHTTPOption) {
+			t.Errorf("Expected ErrInvalidHTTPOption for %+v, got %v", opts, err)
+		}
+	}
+}
+
+func Test_ProbeURL_HTTPOptions(t *testing.T) {
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	fileServer := http.FileServer(http.Dir("./assets"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		cookie, _ := r.Cookie("session")
+		if r.Header.Get("X-Token") != "abc" || r.UserAgent() != "test-agent" ||
+			user != "user" || pass != "pass" || cookie == nil || cookie.Value != "123" {
+			t.Errorf("Unexpected request headers: %v", r.Header)
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		fileServer.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	prober := &Prober{HTTPOptions: &HTTPOptions{
+		Headers:   http.Header{"X-Token": {"abc"}},
+		Username:  "user",
+		Password:  "pass",
+		Cookies:   []*http.Cookie{{Name: "session", Value: "123"}},
+		UserAgent: "test-agent",
+	}}
+
+	data, err := prober.ProbeURL(ctx, server.URL+"/test.mp4")
+	if err != nil {
+		t.Fatalf("Error getting data: %v", err)
+	}
+
+	validateData(t, data)
+}
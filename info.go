@@ -0,0 +1,58 @@
+package ffprobe
+
+import (
+	"io"
+	"time"
+)
+
+// InputKind is the kind of input that was probed
+type InputKind string
+
+const (
+	// InputFile is a local file probed using ProbeURL
+	InputFile InputKind = "file"
+	// InputURL is any other URL probed using ProbeURL
+	InputURL InputKind = "url"
+	// InputReader is a reader probed using ProbeReader
+	InputReader InputKind = "reader"
+)
+
+// ProbeInfo describes how probe data was produced
+type ProbeInfo struct {
+	// FFProbeVersion is the first line of the version output of ffprobe, empty when it could not be determined
+	FFProbeVersion string
+	// Args is the full command line, starting with the path of the ffprobe binary. The values of the headers and
+	// cookies options and passwords in URLs are redacted.
+	Args []string
+	// StartTime is the time ffprobe was started
+	StartTime time.Time
+	// Duration is the wall time between starting ffprobe and processing its output
+	Duration time.Duration
+	// InputKind is the kind of input that was probed
+	InputKind InputKind
+	// BytesRead is the amount of bytes read from the reader, only set for readers
+	BytesRead int64
+}
+
+// clone returns a deep copy of the info
+func (i *ProbeInfo) clone() *ProbeInfo {
+	if i == nil {
+		return nil
+	}
+	clone := *i
+	clone.Args = append([]string(nil), i.Args...)
+	return &clone
+}
+
+// inputKind returns the kind of input probed with the given arguments
+func inputKind(args []string, input io.Reader) InputKind {
+	if input != nil {
+		return InputReader
+	}
+	if len(args) > 0 {
+		if _, ok := localPath(args[len(args)-1]); ok {
+			return InputFile
+		}
+	}
+	return InputURL
+}
@@ -0,0 +1,71 @@
+//go:build !windows
+// +build !windows
+
+package ffprobe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_ProbeInfo(t *testing.T) {
+	defer useFakeFFProbe(t, `[ "$1" = "-version" ] && { echo "ffprobe version 9.9"; echo "more"; exit 0; }
+cat > /dev/null
+echo '`+fakeProbeOutput+`'`)()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	data, err := ProbeURL(ctx, testPath)
+	if err != nil {
+		t.Fatalf("Error getting data: %v", err)
+	}
+	if data.Info != nil {
+		t.Errorf("Expected no info by default")
+	}
+
+	prober := &Prober{CollectInfo: true}
+	before := time.Now()
+	data, err = prober.ProbeURL(ctx, testPath)
+	if err != nil {
+		t.Fatalf("Error getting data: %v", err)
+	}
+
+	info := data.Info
+	if info == nil {
+		t.Fatalf("Expected info")
+	}
+	if info.FFProbeVersion != "ffprobe version 9.9" {
+		t.Errorf("Unexpected version %q", info.FFProbeVersion)
+	}
+	if info.Args[0] != binPath || info.Args[len(info.Args)-1] != testPath {
+		t.Errorf("Unexpected args %v", info.Args)
+	}
+	if info.StartTime.Before(before) || info.Duration <= 0 {
+		t.Errorf("Unexpected timing %v, %v", info.StartTime, info.Duration)
+	}
+	if info.InputKind != InputFile {
+		t.Errorf("Unexpected input kind %s", info.InputKind)
+	}
+
+	data, err = prober.ProbeReader(ctx, bytes.NewReader(make([]byte, 100000)))
+	if err != nil {
+		t.Fatalf("Error getting data: %v", err)
+	}
+	if data.Info.InputKind != InputReader || data.Info.BytesRead != 100000 {
+		t.Errorf("Unexpected reader info %s, %d", data.Info.InputKind, data.Info.BytesRead)
+	}
+
+	// The info is not part of the ffprobe compatible JSON
+	buf, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("Error marshalling: %v", err)
+	}
+	if strings.Contains(string(buf), "9.9") {
+		t.Errorf("Info should not be marshalled: %s", buf)
+	}
+}
@@ -0,0 +1,138 @@
+package ffprobe
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// InputReadError is returned by ProbeReader when reading from the supplied reader failed
+type InputReadError struct {
+	Err error
+}
+
+func (e *InputReadError) Error() string {
+	return fmt.Sprintf("error reading input: %v", e.Err)
+}
+
+// Unwrap returns the error returned by the reader
+func (e *InputReadError) Unwrap() error {
+	return e.Err
+}
+
+// inputCopier copies the input of a probe to the stdin of ffprobe, until the input ends, ffprobe stops reading or
+// the copy is stopped.
+type inputCopier struct {
+	// read is the amount of bytes read from src, accessed atomically. It is the first field to keep it aligned.
+	read int64
+
+	src      io.Reader
+	dst      io.WriteCloser
+	finished chan struct{}
+	errs     chan error
+
+	mu      sync.Mutex
+	reading bool
+	stopped bool
+}
+
+func newInputCopier(src io.Reader, dst io.WriteCloser) *inputCopier {
+	return &inputCopier{
+		src:      src,
+		dst:      dst,
+		finished: make(chan struct{}),
+		errs:     make(chan error, 1),
+	}
+}
+
+// run copies the input and closes stdin when done. Any error is recorded before stdin is closed, so any error that
+// could have influenced ffprobe is known by the time the process has exited.
+func (c *inputCopier) run() {
+	defer close(c.finished)
+	err := c.copy()
+	if err != nil {
+		c.errs <- err
+	}
+	_ = c.dst.Close()
+}
+
+func (c *inputCopier) copy() error {
+	buf := make([]byte, 32*1024)
+	for {
+		if !c.startRead() {
+			return nil
+		}
+		n, readErr := c.src.Read(buf)
+		atomic.AddInt64(&c.read, int64(n))
+		if !c.endRead() {
+			// ffprobe has exited while reading, anything this read returned is of no use anymore
+			return nil
+		}
+
+		if n > 0 {
+			_, writeErr := c.dst.Write(buf[:n])
+			if writeErr != nil {
+				if isBrokenPipe(writeErr) || errors.Is(writeErr, os.ErrClosed) {
+					// ffprobe exited or stopped reading, it has all the data it wants
+					return nil
+				}
+				return fmt.Errorf("error writing to ffprobe stdin: %w", writeErr)
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return &InputReadError{Err: readErr}
+		}
+	}
+}
+
+// startRead marks a read on the input as pending, it returns false when the copy has been stopped
+func (c *inputCopier) startRead() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reading = !c.stopped
+	return c.reading
+}
+
+// endRead marks the pending read as done, it returns false when the copy has been stopped meanwhile
+func (c *inputCopier) endRead() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reading = false
+	return !c.stopped
+}
+
+// stop stops the copy once ffprobe has exited, and waits for it to finish unless a read on the input is pending.
+// A pending read may block indefinitely, its result is ignored. Afterwards err reports exactly the errors that
+// happened before the copy was stopped.
+func (c *inputCopier) stop() {
+	c.mu.Lock()
+	c.stopped = true
+	reading := c.reading
+	c.mu.Unlock()
+
+	if !reading {
+		// The copy is writing to stdin, which is closed by now, or it has finished already
+		<-c.finished
+	}
+}
+
+// bytesRead returns the amount of bytes read from the input so far
+func (c *inputCopier) bytesRead() int64 {
+	return atomic.LoadInt64(&c.read)
+}
+
+// err returns the error the copy ended with, if it ended with an error before it was stopped
+func (c *inputCopier) err() error {
+	select {
+	case err := <-c.errs:
+		return err
+	default:
+		return nil
+	}
+}
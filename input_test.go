@@ -0,0 +1,151 @@
+//go:build !windows
+// +build !windows
+
+package ffprobe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// endlessReader returns zero bytes forever
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+// blockingReader returns some data and then blocks until it is released
+type blockingReader struct {
+	data    []byte
+	release chan struct{}
+}
+
+func (r *blockingReader) Read(p []byte) (int, error) {
+	if len(r.data) > 0 {
+		n := copy(p, r.data)
+		r.data = r.data[n:]
+		return n, nil
+	}
+	<-r.release
+	return 0, io.EOF
+}
+
+// failingReader returns some data and then an error
+type failingReader struct {
+	data []byte
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if len(r.data) > 0 {
+		n := copy(p, r.data)
+		r.data = r.data[n:]
+		return n, nil
+	}
+	return 0, r.err
+}
+
+// slowFailingReader returns some data and then an error after a delay
+type slowFailingReader struct {
+	data  []byte
+	delay time.Duration
+	err   error
+}
+
+func (r *slowFailingReader) Read(p []byte) (int, error) {
+	if len(r.data) > 0 {
+		n := copy(p, r.data)
+		r.data = r.data[n:]
+		return n, nil
+	}
+	time.Sleep(r.delay)
+	return 0, r.err
+}
+
+func Test_ProbeReader_EarlyExit(t *testing.T) {
+	defer useFakeFFProbe(t, "head -c 1024 > /dev/null\necho '"+fakeProbeOutput+"'")()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	data, err := ProbeReader(ctx, endlessReader{})
+	if err != nil {
+		t.Fatalf("Error getting data: %v", err)
+	}
+	if data.Format == nil {
+		t.Errorf("Format was nil")
+	}
+}
+
+func Test_ProbeReader_SlowReader(t *testing.T) {
+	defer useFakeFFProbe(t, "head -c 4 > /dev/null\necho '"+fakeProbeOutput+"'")()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	reader := &blockingReader{data: []byte("data"), release: make(chan struct{})}
+	defer close(reader.release)
+
+	_, err := ProbeReader(ctx, reader)
+	if err != nil {
+		t.Fatalf("Error getting data: %v", err)
+	}
+}
+
+func Test_ProbeReader_ReadError(t *testing.T) {
+	defer useFakeFFProbe(t, "cat > /dev/null\necho '"+fakeProbeOutput+"'")()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	readErr := errors.New("disk on fire")
+	_, err := ProbeReader(ctx, &failingReader{data: []byte("data"), err: readErr})
+
+	var inputErr *InputReadError
+	if !errors.As(err, &inputErr) {
+		t.Fatalf("Expected an InputReadError, got %v", err)
+	}
+	if !errors.Is(err, readErr) {
+		t.Errorf("Expected the error to wrap the read error, got %v", err)
+	}
+}
+
+func Test_ProbeReader_ReadErrorAfterExit(t *testing.T) {
+	defer useFakeFFProbe(t, "head -c 4 > /dev/null\necho '"+fakeProbeOutput+"'")()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	// The read error only happens once ffprobe has exited successfully, it must be ignored
+	reader := &slowFailingReader{data: []byte("data"), delay: 200 * time.Millisecond, err: errors.New("disk on fire")}
+	_, err := ProbeReader(ctx, reader)
+	if err != nil {
+		t.Fatalf("Error getting data: %v", err)
+	}
+}
+
+func Test_ProbeReader_ReadLimit(t *testing.T) {
+	// Output the amount of bytes received as the format name
+	defer useFakeFFProbe(t, `echo "{\"format\":{\"format_name\":\"$(wc -c | tr -d ' ')\"}}"`)()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	prober := &Prober{ReadLimit: 100}
+	data, err := prober.ProbeReader(ctx, bytes.NewReader(bytes.Repeat([]byte{1}, 1000)))
+	if err != nil {
+		t.Fatalf("Error getting data: %v", err)
+	}
+	if strings.TrimSpace(data.Format.FormatName) != "100" {
+		t.Errorf("Expected 100 bytes to be read, got %s", data.Format.FormatName)
+	}
+}
@@ -0,0 +1,61 @@
+package ffprobe
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keyframe is the position of a keyframe in a stream
+type Keyframe struct {
+	// PTS is the presentation timestamp of the keyframe
+	PTS time.Duration
+	// Pos is the byte position of the keyframe packet in the file, -1 when unknown
+	Pos int64
+	// Size is the size of the keyframe packet in bytes
+	Size int
+}
+
+// Keyframes returns the keyframes of the stream with the given index, using the packet flags so no decoding is
+// needed. The output of ffprobe is processed while it runs, so it is never buffered in full.
+func Keyframes(ctx context.Context, fileURL string, streamIndex int) ([]Keyframe, error) {
+	return defaultProber.Keyframes(ctx, fileURL, streamIndex)
+}
+
+// Keyframes returns the keyframes of the stream with the given index, using the configuration of the Prober.
+// See the package level Keyframes function for more details.
+func (p *Prober) Keyframes(ctx context.Context, fileURL string, streamIndex int) ([]Keyframe, error) {
+	args := []string{
+		"-select_streams", strconv.Itoa(streamIndex),
+		"-show_entries", "packet=pts_time,dts_time,size,pos,flags",
+	}
+
+	var keyframes []Keyframe
+	err := p.streamEntries(ctx, fileURL, args, func(e entries) error {
+		if e[sectionKey] != "packet" || !strings.HasPrefix(e["flags"], "K") {
+			return nil
+		}
+
+		pts, ok := e.duration("pts_time")
+		if !ok {
+			// Fall back to the decoding timestamp, which equals the presentation timestamp for most keyframes
+			pts, ok = e.duration("dts_time")
+			if !ok {
+				return nil
+			}
+		}
+		pos, ok := e.int("pos")
+		if !ok {
+			pos = -1
+		}
+		size, _ := e.int("size")
+
+		keyframes = append(keyframes, Keyframe{PTS: pts, Pos: pos, Size: int(size)})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keyframes, nil
+}
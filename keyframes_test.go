@@ -0,0 +1,33 @@
+package ffprobe
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_Keyframes(t *testing.T) {
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	data, err := ProbeURL(ctx, testPath)
+	if err != nil {
+		t.Fatalf("Error getting data: %v", err)
+	}
+
+	keyframes, err := Keyframes(ctx, testPath, data.FirstVideoStream().Index)
+	if err != nil {
+		t.Fatalf("Error getting keyframes: %v", err)
+	}
+	if len(keyframes) == 0 {
+		t.Fatalf("Expected keyframes")
+	}
+	if keyframes[0].PTS != 0 || keyframes[0].Pos <= 0 || keyframes[0].Size <= 0 {
+		t.Errorf("Unexpected first keyframe: %+v", keyframes[0])
+	}
+	for i := 1; i < len(keyframes); i++ {
+		if keyframes[i].PTS <= keyframes[i-1].PTS {
+			t.Errorf("Keyframes are not in order: %+v", keyframes)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+//go:build !windows
+// +build !windows
+
+package ffprobe
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Keyframes_Parse(t *testing.T) {
+	defer useFakeFFProbe(t, `printf 'packet|pts_time=0.000000|dts_time=-0.080000|size=5000|pos=48|flags=K__\n'
+printf 'packet|pts_time=0.040000|dts_time=0.000000|size=300|pos=5048|flags=___\n'
+printf 'packet|pts_time=N/A|dts_time=2.000000|size=4000|pos=N/A|flags=K__\n'
+printf 'packet|pts_time=4.000000|dts_time=4.000000|size=4100|pos=90000|flags=K_'`)()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	keyframes, err := Keyframes(ctx, testPath, 0)
+	if err != nil {
+		t.Fatalf("Error getting keyframes: %v", err)
+	}
+
+	expected := []Keyframe{
+		{PTS: 0, Pos: 48, Size: 5000},
+		{PTS: 2 * time.Second, Pos: -1, Size: 4000},
+		{PTS: 4 * time.Second, Pos: 90000, Size: 4100},
+	}
+	if !reflect.DeepEqual(keyframes, expected) {
+		t.Errorf("Unexpected keyframes:\n%+v\nexpected:\n%+v", keyframes, expected)
+	}
+}
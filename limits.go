@@ -0,0 +1,70 @@
+package ffprobe
+
+import (
+	"bytes"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrMemoryLimit is returned when ffprobe most likely failed because it hit the address space limit
+	ErrMemoryLimit = errors.New("ffprobe memory limit exceeded")
+	// ErrCPULimit is returned when ffprobe was killed because it used up its CPU time
+	ErrCPULimit = errors.New("ffprobe CPU time limit exceeded")
+	// ErrOpenFilesLimit is returned when ffprobe most likely failed because it could not open any more files
+	ErrOpenFilesLimit = errors.New("ffprobe open files limit exceeded")
+	// ErrOutputLimit is returned when ffprobe wrote more output than allowed
+	ErrOutputLimit = errors.New("ffprobe output limit exceeded")
+	// ErrResourceLimitsUnsupported is returned when resource limits are configured on a platform that does not support them
+	ErrResourceLimitsUnsupported = errors.New("resource limits are not supported on this platform")
+)
+
+// ResourceLimits are the operating system resource limits applied to the ffprobe process. They are only supported on
+// linux, where they are set by a /bin/sh wrapper that then executes ffprobe, so they are in place before ffprobe runs.
+// Any process ffprobe spawns inherits them. A zero value for any of the limits means that limit is not changed.
+type ResourceLimits struct {
+	// AddressSpace is the maximum size of the virtual memory of ffprobe in bytes, rounded down to whole KiB (RLIMIT_AS)
+	AddressSpace uint64
+	// CPUTime is the maximum amount of CPU time ffprobe may use, rounded up to whole seconds (RLIMIT_CPU)
+	CPUTime time.Duration
+	// OpenFiles is the maximum number of file descriptors ffprobe may have open (RLIMIT_NOFILE)
+	OpenFiles uint64
+	// DisableCoreDumps prevents ffprobe from writing a core dump when it crashes (RLIMIT_CORE)
+	DisableCoreDumps bool
+}
+
+// cpuSeconds returns the CPU time limit in whole seconds
+func (l *ResourceLimits) cpuSeconds() uint64 {
+	return uint64((l.CPUTime + time.Second - 1) / time.Second)
+}
+
+// limitedBuffer is a buffer refusing any writes beyond its limit. It deliberately does not embed bytes.Buffer, as
+// io.Copy would then bypass the limit using its ReadFrom method.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int64
+	exceeded bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit > 0 && int64(b.buf.Len()+len(p)) > b.limit {
+		b.exceeded = true
+		return 0, ErrOutputLimit
+	}
+	return b.buf.Write(p)
+}
+
+// Bytes returns the buffered bytes
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+// Len returns the amount of buffered bytes
+func (b *limitedBuffer) Len() int {
+	return b.buf.Len()
+}
+
+// String returns the buffered bytes as a string
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
@@ -0,0 +1,64 @@
+package ffprobe
+
+import (
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const resourceLimitsSupported = true
+
+// limitedCommand returns the command running ffprobe with the limits applied. A shell sets the limits and then replaces
+// itself with ffprobe, as the limits of a process cannot be set between fork and exec from Go.
+func limitedCommand(limits *ResourceLimits, path string, args []string) *exec.Cmd {
+	var script []string
+	if limits.AddressSpace > 0 {
+		kib := limits.AddressSpace / 1024
+		if kib == 0 {
+			kib = 1
+		}
+		script = append(script, "ulimit -v "+strconv.FormatUint(kib, 10))
+	}
+	if limits.CPUTime > 0 {
+		// Keep the hard limit a second higher, so the process receives SIGXCPU instead of SIGKILL
+		secs := limits.cpuSeconds()
+		script = append(script,
+			"ulimit -S -t "+strconv.FormatUint(secs, 10),
+			"ulimit -H -t "+strconv.FormatUint(secs+1, 10))
+	}
+	if limits.OpenFiles > 0 {
+		script = append(script, "ulimit -n "+strconv.FormatUint(limits.OpenFiles, 10))
+	}
+	if limits.DisableCoreDumps {
+		script = append(script, "ulimit -c 0")
+	}
+	script = append(script, `exec "$0" "$@"`)
+
+	return exec.Command("/bin/sh", append([]string{"-c", strings.Join(script, " && "), path}, args...)...) //nolint:gosec
+}
+
+// resourceLimitError returns the limit error matching the way ffprobe failed, or nil when it did not hit any limit
+func resourceLimitError(limits *ResourceLimits, runErr error, stdErr string) error {
+	var exitErr *exec.ExitError
+	if !errors.As(runErr, &exitErr) {
+		return nil
+	}
+
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if ok && status.Signaled() && status.Signal() == syscall.SIGXCPU && limits.CPUTime > 0 {
+		return ErrCPULimit
+	}
+
+	// A crash is only attributed to the memory limit when ffprobe reported an allocation failure before
+	if limits.AddressSpace > 0 &&
+		(strings.Contains(stdErr, "Cannot allocate memory") || strings.Contains(stdErr, "out of memory")) {
+		return ErrMemoryLimit
+	}
+
+	if limits.OpenFiles > 0 && strings.Contains(stdErr, "Too many open files") {
+		return ErrOpenFilesLimit
+	}
+	return nil
+}
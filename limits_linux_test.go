@@ -0,0 +1,98 @@
+package ffprobe
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_ResourceLimits_Applied(t *testing.T) {
+	// Report the limits as the format name right away, they must be in place before ffprobe runs
+	defer useFakeFFProbe(t, `echo "{\"format\":{\"format_name\":\"$(ulimit -n) $(ulimit -c) $(ulimit -v) $1\"}}"`)()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	prober := &Prober{ResourceLimits: &ResourceLimits{
+		AddressSpace:     4 << 30,
+		OpenFiles:        64,
+		DisableCoreDumps: true,
+	}}
+	data, err := prober.ProbeURL(ctx, testPath)
+	if err != nil {
+		t.Fatalf("Error getting data: %v", err)
+	}
+	// The arguments are passed to ffprobe unchanged
+	if data.Format.FormatName != "64 0 4194304 -loglevel" {
+		t.Errorf("Expected limits '64 0 4194304' and the first argument, got %q", data.Format.FormatName)
+	}
+}
+
+func Test_ResourceLimits_Crash(t *testing.T) {
+	// A crash without an allocation failure is not attributed to the memory limit
+	defer useFakeFFProbe(t, "kill -SEGV $$")()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	prober := &Prober{ResourceLimits: &ResourceLimits{AddressSpace: 4 << 30}}
+	_, err := prober.ProbeURL(ctx, testPath)
+	var runErr *RunError
+	if !errors.As(err, &runErr) || errors.Is(err, ErrMemoryLimit) {
+		t.Errorf("Expected a RunError without ErrMemoryLimit, got %v", err)
+	}
+}
+
+func Test_ResourceLimits_CPU(t *testing.T) {
+	defer useFakeFFProbe(t, "while :; do :; done")()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFn()
+
+	prober := &Prober{ResourceLimits: &ResourceLimits{CPUTime: time.Second}}
+	_, err := prober.ProbeURL(ctx, testPath)
+	if !errors.Is(err, ErrCPULimit) {
+		t.Errorf("Expected ErrCPULimit, got %v", err)
+	}
+	var runErr *RunError
+	if !errors.As(err, &runErr) {
+		t.Fatalf("Expected a RunError, got %v", err)
+	}
+	if runErr.ExitCode != -1 {
+		t.Errorf("Expected exit code -1 for a killed process, got %d", runErr.ExitCode)
+	}
+}
+
+func Test_ResourceLimits_Memory(t *testing.T) {
+	defer useFakeFFProbe(t, "echo 'Cannot allocate memory' >&2\nexit 1")()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	prober := &Prober{ResourceLimits: &ResourceLimits{AddressSpace: 512 << 20}}
+	_, err := prober.ProbeURL(ctx, testPath)
+	if !errors.Is(err, ErrMemoryLimit) {
+		t.Errorf("Expected ErrMemoryLimit, got %v", err)
+	}
+	var runErr *RunError
+	if !errors.As(err, &runErr) {
+		t.Fatalf("Expected a RunError, got %v", err)
+	}
+	if runErr.ExitCode != 1 {
+		t.Errorf("Expected exit code 1, got %d", runErr.ExitCode)
+	}
+}
+
+func Test_MaxOutputSize(t *testing.T) {
+	defer useFakeFFProbe(t, "yes '"+fakeProbeOutput+"'")()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	prober := &Prober{MaxOutputSize: 4096}
+	_, err := prober.ProbeURL(ctx, testPath)
+	if !errors.Is(err, ErrOutputLimit) {
+		t.Errorf("Expected ErrOutputLimit, got %v", err)
+	}
+}
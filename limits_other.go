@@ -0,0 +1,16 @@
+//go:build !linux
+// +build !linux
+
+package ffprobe
+
+import "os/exec"
+
+const resourceLimitsSupported = false
+
+func limitedCommand(_ *ResourceLimits, path string, args []string) *exec.Cmd {
+	return exec.Command(path, args...) //nolint:gosec
+}
+
+func resourceLimitError(_ *ResourceLimits, _ error, _ string) error {
+	return nil
+}
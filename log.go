@@ -0,0 +1,77 @@
+package ffprobe
+
+import (
+	"strings"
+)
+
+// LogLevel is an ffmpeg log level
+type LogLevel string
+
+// The log levels supported by ffmpeg, from least to most verbose
+const (
+	LogLevelQuiet   LogLevel = "quiet"
+	LogLevelPanic   LogLevel = "panic"
+	LogLevelFatal   LogLevel = "fatal"
+	LogLevelError   LogLevel = "error"
+	LogLevelWarning LogLevel = "warning"
+	LogLevelInfo    LogLevel = "info"
+	LogLevelVerbose LogLevel = "verbose"
+	LogLevelDebug   LogLevel = "debug"
+	LogLevelTrace   LogLevel = "trace"
+)
+
+var logLevels = map[string]LogLevel{
+	string(LogLevelQuiet):   LogLevelQuiet,
+	string(LogLevelPanic):   LogLevelPanic,
+	string(LogLevelFatal):   LogLevelFatal,
+	string(LogLevelError):   LogLevelError,
+	string(LogLevelWarning): LogLevelWarning,
+	string(LogLevelInfo):    LogLevelInfo,
+	string(LogLevelVerbose): LogLevelVerbose,
+	string(LogLevelDebug):   LogLevelDebug,
+	string(LogLevelTrace):   LogLevelTrace,
+}
+
+// LogEntry is a single line ffprobe logged
+type LogEntry struct {
+	// Level is the level the line was logged at, empty when ffprobe does not report it
+	Level LogLevel
+	// Component is the name of the component that logged the line, like "mov,mp4,m4a,3gp,3g2,mj2" or "h264".
+	// It is empty for lines not logged by a specific component.
+	Component string
+	// Message is the logged message
+	Message string
+}
+
+// parseLog parses the stderr output of ffprobe into log entries. Every line is expected to look like
+// "[component @ 0x55d0c0a5c2c0] [warning] message", where the component and level are optional.
+func parseLog(stdErr string) []LogEntry {
+	var entries []LogEntry
+	for _, line := range strings.Split(stdErr, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		var entry LogEntry
+		for strings.HasPrefix(line, "[") {
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				break
+			}
+			prefix := line[1:end]
+			if idx := strings.Index(prefix, " @ "); idx >= 0 {
+				// Nested components log the prefix of their parent first, keep the innermost one
+				entry.Component = prefix[:idx]
+			} else if level, ok := logLevels[prefix]; ok {
+				entry.Level = level
+			} else {
+				break
+			}
+			line = strings.TrimLeft(line[end+1:], " ")
+		}
+		entry.Message = line
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,28 @@
+package ffprobe
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseLog(t *testing.T) {
+	stdErr := "[mov,mp4,m4a,3gp,3g2,mj2 @ 0x55d0c0a5c2c0] [warning] moov atom not found\n" +
+		"[hls @ 0x1] [https @ 0x2] [error] Connection reset\r\n" +
+		"[warning] non-monotonous DTS\n" +
+		"\n" +
+		"[h264 @ 0x3] no level\n" +
+		"plain message [with brackets]\n"
+
+	expected := []LogEntry{
+		{Level: LogLevelWarning, Component: "mov,mp4,m4a,3gp,3g2,mj2", Message: "moov atom not found"},
+		{Level: LogLevelError, Component: "https", Message: "Connection reset"},
+		{Level: LogLevelWarning, Message: "non-monotonous DTS"},
+		{Component: "h264", Message: "no level"},
+		{Message: "plain message [with brackets]"},
+	}
+
+	entries := parseLog(stdErr)
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Unexpected entries:\n%+v\nexpected:\n%+v", entries, expected)
+	}
+}
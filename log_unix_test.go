@@ -0,0 +1,66 @@
+//go:build !windows
+// +build !windows
+
+package ffprobe
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_ProbeURL_Warnings(t *testing.T) {
+	// Report the log level as format name, and log a warning
+	defer useFakeFFProbe(t, `echo "[mov,mp4,m4a,3gp,3g2,mj2 @ 0x1] [warning] moov atom not found" >&2
+echo "{\"format\":{\"format_name\":\"$2\"}}"`)()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	data, err := ProbeURL(ctx, testPath)
+	if err != nil {
+		t.Fatalf("Error getting data: %v", err)
+	}
+	if data.Format.FormatName != "fatal" || len(data.Warnings) != 0 {
+		t.Errorf("Expected no warnings by default, got %q and %v", data.Format.FormatName, data.Warnings)
+	}
+
+	prober := &Prober{LogLevel: LogLevelWarning}
+	data, err = prober.ProbeURL(ctx, testPath)
+	if err != nil {
+		t.Fatalf("Error getting data: %v", err)
+	}
+	if data.Format.FormatName != "level+warning" {
+		t.Errorf("Unexpected log level %q", data.Format.FormatName)
+	}
+	if len(data.Warnings) != 1 || data.Warnings[0].Level != LogLevelWarning || data.Warnings[0].Message != "moov atom not found" {
+		t.Errorf("Unexpected warnings: %+v", data.Warnings)
+	}
+}
+
+func Test_ProbeURL_LogLevelWithRetry(t *testing.T) {
+	defer useFakeFFProbe(t, `echo "{\"format\":{\"format_name\":\"$2\"}}"`)()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	tests := []struct {
+		level    LogLevel
+		expected string
+	}{
+		{"", "error"},
+		{LogLevelQuiet, "level+error"},
+		{LogLevelFatal, "level+error"},
+		{LogLevelInfo, "level+info"},
+	}
+	for _, tt := range tests {
+		prober := &Prober{LogLevel: tt.level, Retry: &RetryPolicy{MaxAttempts: 2}}
+		data, err := prober.ProbeURL(ctx, testPath)
+		if err != nil {
+			t.Fatalf("Error getting data: %v", err)
+		}
+		if data.Format.FormatName != tt.expected {
+			t.Errorf("Expected log level %q for %q, got %q", tt.expected, tt.level, data.Format.FormatName)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package ffprobe
+
+import (
+	"fmt"
+	"math"
+)
+
+// Chromaticity is a CIE 1931 xy chromaticity coordinate
+type Chromaticity struct {
+	X float64
+	Y float64
+}
+
+// SMPTE2086 is mastering display metadata as coded by SMPTE ST 2086 and the HEVC mastering display colour volume SEI.
+// Chromaticity coordinates are in units of 0.00002 and luminance in units of 0.0001 cd/m².
+type SMPTE2086 struct {
+	Red          [2]uint16
+	Green        [2]uint16
+	Blue         [2]uint16
+	WhitePoint   [2]uint16
+	MaxLuminance uint32
+	MinLuminance uint32
+}
+
+// HasPrimaries returns whether the display primaries and white point are set
+func (m *SideDataMasteringDisplayMetadata) HasPrimaries() bool {
+	return m.RedX.Den != 0 && m.RedY.Den != 0 && m.GreenX.Den != 0 && m.GreenY.Den != 0 &&
+		m.BlueX.Den != 0 && m.BlueY.Den != 0 && m.WhitePointX.Den != 0 && m.WhitePointY.Den != 0
+}
+
+// HasLuminance returns whether the minimum and maximum luminance are set
+func (m *SideDataMasteringDisplayMetadata) HasLuminance() bool {
+	return m.MinLuminance.Den != 0 && m.MaxLuminance.Den != 0
+}
+
+// Red returns the chromaticity of the red display primary
+func (m *SideDataMasteringDisplayMetadata) Red() Chromaticity {
+	return Chromaticity{X: m.RedX.Float64(), Y: m.RedY.Float64()}
+}
+
+// Green returns the chromaticity of the green display primary
+func (m *SideDataMasteringDisplayMetadata) Green() Chromaticity {
+	return Chromaticity{X: m.GreenX.Float64(), Y: m.GreenY.Float64()}
+}
+
+// Blue returns the chromaticity of the blue display primary
+func (m *SideDataMasteringDisplayMetadata) Blue() Chromaticity {
+	return Chromaticity{X: m.BlueX.Float64(), Y: m.BlueY.Float64()}
+}
+
+// WhitePoint returns the chromaticity of the white point
+func (m *SideDataMasteringDisplayMetadata) WhitePoint() Chromaticity {
+	return Chromaticity{X: m.WhitePointX.Float64(), Y: m.WhitePointY.Float64()}
+}
+
+// MinLuminanceNits returns the minimum luminance of the mastering display in cd/m²
+func (m *SideDataMasteringDisplayMetadata) MinLuminanceNits() float64 {
+	return m.MinLuminance.Float64()
+}
+
+// MaxLuminanceNits returns the maximum luminance of the mastering display in cd/m²
+func (m *SideDataMasteringDisplayMetadata) MaxLuminanceNits() float64 {
+	return m.MaxLuminance.Float64()
+}
+
+// SMPTE2086 returns the metadata in the coded units of SMPTE ST 2086
+func (m *SideDataMasteringDisplayMetadata) SMPTE2086() SMPTE2086 {
+	return SMPTE2086{
+		Red:          m.Red().coded(),
+		Green:        m.Green().coded(),
+		Blue:         m.Blue().coded(),
+		WhitePoint:   m.WhitePoint().coded(),
+		MaxLuminance: uint32(math.Round(m.MaxLuminanceNits() * 10000)),
+		MinLuminance: uint32(math.Round(m.MinLuminanceNits() * 10000)),
+	}
+}
+
+// X265MasterDisplay returns the metadata as the value of the x265 master-display option,
+// like "G(13250,34500)B(7500,3000)R(34000,16000)WP(15635,16450)L(10000000,50)"
+func (m *SideDataMasteringDisplayMetadata) X265MasterDisplay() string {
+	return m.SMPTE2086().X265MasterDisplay()
+}
+
+// X265MasterDisplay returns the metadata as the value of the x265 master-display option
+func (s SMPTE2086) X265MasterDisplay() string {
+	return fmt.Sprintf("G(%d,%d)B(%d,%d)R(%d,%d)WP(%d,%d)L(%d,%d)",
+		s.Green[0], s.Green[1], s.Blue[0], s.Blue[1], s.Red[0], s.Red[1],
+		s.WhitePoint[0], s.WhitePoint[1], s.MaxLuminance, s.MinLuminance)
+}
+
+// String returns the metadata in real units, like
+// "R(0.68000,0.32000) G(0.26500,0.69000) B(0.15000,0.06000) WP(0.31270,0.32900) L(1000.0000,0.0050)"
+func (s SMPTE2086) String() string {
+	return fmt.Sprintf("R(%.5f,%.5f) G(%.5f,%.5f) B(%.5f,%.5f) WP(%.5f,%.5f) L(%.4f,%.4f)",
+		float64(s.Red[0])*0.00002, float64(s.Red[1])*0.00002,
+		float64(s.Green[0])*0.00002, float64(s.Green[1])*0.00002,
+		float64(s.Blue[0])*0.00002, float64(s.Blue[1])*0.00002,
+		float64(s.WhitePoint[0])*0.00002, float64(s.WhitePoint[1])*0.00002,
+		float64(s.MaxLuminance)*0.0001, float64(s.MinLuminance)*0.0001)
+}
+
+// coded returns the chromaticity in units of 0.00002
+func (c Chromaticity) coded() [2]uint16 {
+	return [2]uint16{uint16(math.Round(c.X * 50000)), uint16(math.Round(c.Y * 50000))}
+}
@@ -0,0 +1,67 @@
+package ffprobe
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const masteringDisplayJSON = `{
+	"side_data_type": "Mastering display metadata",
+	"red_x": "34000/50000",
+	"red_y": "16000/50000",
+	"green_x": "13250/50000",
+	"green_y": "34500/50000",
+	"blue_x": "7500/50000",
+	"blue_y": "3000/50000",
+	"white_point_x": "15635/50000",
+	"white_point_y": "16450/50000",
+	"min_luminance": "50/10000",
+	"max_luminance": "10000000/10000"
+}`
+
+func Test_MasteringDisplayMetadata(t *testing.T) {
+	var sd SideData
+	if err := json.Unmarshal([]byte(masteringDisplayJSON), &sd); err != nil {
+		t.Fatalf("Error unmarshalling side data: %v", err)
+	}
+	m, err := SideDataList{sd}.GetMasteringDisplayMetadata()
+	if err != nil {
+		t.Fatalf("Error getting mastering display metadata: %v", err)
+	}
+
+	if !m.HasPrimaries() || !m.HasLuminance() {
+		t.Errorf("Expected primaries and luminance to be set")
+	}
+	if red := m.Red(); red != (Chromaticity{X: 0.68, Y: 0.32}) {
+		t.Errorf("Expected red primary (0.68, 0.32), got %v", red)
+	}
+	if wp := m.WhitePoint(); wp != (Chromaticity{X: 0.3127, Y: 0.329}) {
+		t.Errorf("Expected white point (0.3127, 0.329), got %v", wp)
+	}
+	if m.MaxLuminanceNits() != 1000 || m.MinLuminanceNits() != 0.005 {
+		t.Errorf("Expected luminance between 0.005 and 1000 cd/m², got %f and %f",
+			m.MinLuminanceNits(), m.MaxLuminanceNits())
+	}
+
+	expected := "G(13250,34500)B(7500,3000)R(34000,16000)WP(15635,16450)L(10000000,50)"
+	if str := m.X265MasterDisplay(); str != expected {
+		t.Errorf("Expected x265 master-display %q, got %q", expected, str)
+	}
+	expected = "R(0.68000,0.32000) G(0.26500,0.69000) B(0.15000,0.06000) WP(0.31270,0.32900) L(1000.0000,0.0050)"
+	if str := m.SMPTE2086().String(); str != expected {
+		t.Errorf("Expected SMPTE ST 2086 %q, got %q", expected, str)
+	}
+
+	// The rationals survive a round trip, as used by the disk cache
+	b, err := json.Marshal(&sd)
+	if err != nil {
+		t.Fatalf("Error marshalling side data: %v", err)
+	}
+	var roundTrip SideData
+	if err := json.Unmarshal(b, &roundTrip); err != nil {
+		t.Fatalf("Error unmarshalling side data: %v", err)
+	}
+	if *roundTrip.Data.(*SideDataMasteringDisplayMetadata) != *m {
+		t.Errorf("Expected %+v after round trip, got %+v", m, roundTrip.Data)
+	}
+}
@@ -0,0 +1,66 @@
+package ffprobe
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// OptionalFloat is a number that ffprobe may print as a string or a rational, and as "unknown" when it is not set
+type OptionalFloat struct {
+	Value float64
+	// Valid is false when the value is not set
+	Valid bool
+}
+
+// MarshalJSON marshals the value as a string, like ffprobe prints it
+func (f OptionalFloat) MarshalJSON() ([]byte, error) {
+	if !f.Valid {
+		return json.Marshal("unknown")
+	}
+	return json.Marshal(strconv.FormatFloat(f.Value, 'f', 6, 64))
+}
+
+// UnmarshalJSON unmarshals a number, or a string containing a number, a rational like "-720000/100000" or "unknown"
+func (f *OptionalFloat) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		var value float64
+		if err := json.Unmarshal(b, &value); err != nil {
+			return err
+		}
+		*f = OptionalFloat{Value: value, Valid: true}
+		return nil
+	}
+	if value, err := strconv.ParseFloat(str, 64); err == nil {
+		*f = OptionalFloat{Value: value, Valid: true}
+		return nil
+	}
+	if rational, err := ParseRational(str); err == nil && rational.Den != 0 {
+		*f = OptionalFloat{Value: rational.Float64(), Valid: true}
+		return nil
+	}
+	// Values that are not set are printed as "unknown"
+	*f = OptionalFloat{}
+	return nil
+}
+
+// unmarshalEnum unmarshals an enum that ffprobe may print as a number or by name. Unknown names result in -1.
+func unmarshalEnum(b []byte, names []string) (int, error) {
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		var value int
+		if err := json.Unmarshal(b, &value); err != nil {
+			return 0, err
+		}
+		return value, nil
+	}
+	if value, err := strconv.Atoi(str); err == nil {
+		return value, nil
+	}
+	for i, name := range names {
+		if name == str {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
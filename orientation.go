@@ -0,0 +1,78 @@
+package ffprobe
+
+import "math"
+
+// Orientation is how a video frame must be transformed for display: first rotated clockwise, then flipped
+type Orientation struct {
+	// Rotation is the clockwise rotation in degrees, in the range [0, 360)
+	Rotation int
+	// HFlip is true when the frame is mirrored horizontally, like front camera videos
+	HFlip bool
+	// VFlip is true when the frame is mirrored vertically
+	VFlip bool
+}
+
+// QuarterTurns returns the rotation rounded to the nearest multiple of 90 degrees, as a number of quarter turns
+// in the range [0, 4)
+func (o Orientation) QuarterTurns() int {
+	return (o.Rotation + 45) / 90 % 4
+}
+
+// Orientation resolves how the frames of the stream must be transformed for display. The display matrix side data is
+// used when present, as its sign convention does not depend on the ffprobe version. Otherwise the rotation of the
+// display matrix side data or the rotate tag is used.
+func (s *Stream) Orientation() Orientation {
+	displayMatrix, err := s.SideDataList.GetDisplayMatrix()
+	if err == nil {
+		if matrix, err := displayMatrix.Matrix(); err == nil {
+			if orientation, ok := matrix.orientation(); ok {
+				return orientation
+			}
+		}
+		// The rotation printed by ffprobe is counterclockwise
+		return Orientation{Rotation: normalizeRotation(-displayMatrix.Rotation)}
+	}
+
+	// Older versions of ffprobe print a rotate tag with the clockwise rotation
+	rotate, err := s.TagList.GetInt("rotate")
+	if err == nil {
+		return Orientation{Rotation: normalizeRotation(int(rotate))}
+	}
+	return Orientation{}
+}
+
+// DisplayWidth returns the width of the video as displayed, after applying the sample aspect ratio and rotation
+func (s *Stream) DisplayWidth() int {
+	width, height := s.displaySize()
+	if s.Orientation().QuarterTurns()%2 == 1 {
+		return height
+	}
+	return width
+}
+
+// DisplayHeight returns the height of the video as displayed, after applying the sample aspect ratio and rotation
+func (s *Stream) DisplayHeight() int {
+	width, height := s.displaySize()
+	if s.Orientation().QuarterTurns()%2 == 1 {
+		return width
+	}
+	return height
+}
+
+// displaySize returns the size of the video with the sample aspect ratio applied, stretching the width like ffmpeg
+func (s *Stream) displaySize() (width, height int) {
+	sar, err := ParseRational(s.SampleAspectRatio)
+	if err != nil || sar.Num <= 0 || sar.Den <= 0 {
+		return s.Width, s.Height
+	}
+	return int(math.Round(float64(s.Width) * sar.Float64())), s.Height
+}
+
+// normalizeRotation returns the rotation in degrees in the range [0, 360)
+func normalizeRotation(degrees int) int {
+	degrees %= 360
+	if degrees < 0 {
+		degrees += 360
+	}
+	return degrees
+}
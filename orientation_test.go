@@ -0,0 +1,99 @@
+package ffprobe
+
+import "testing"
+
+func displayMatrixSideData(data string, rotation int) SideDataList {
+	return SideDataList{{
+		SideDataBase: SideDataBase{Type: SideDataTypeDisplayMatrix},
+		Data: &SideDataDisplayMatrix{
+			SideDataBase: SideDataBase{Type: SideDataTypeDisplayMatrix},
+			Data:         data,
+			Rotation:     rotation,
+		},
+	}}
+}
+
+func Test_Orientation(t *testing.T) {
+	tests := []struct {
+		name        string
+		stream      Stream
+		orientation Orientation
+		width       int
+		height      int
+	}{
+		{
+			name:   "none",
+			stream: Stream{Width: 1920, Height: 1080},
+			width:  1920,
+			height: 1080,
+		},
+		{
+			name:        "rotate tag",
+			stream:      Stream{Width: 1920, Height: 1080, TagList: Tags{"rotate": "90"}},
+			orientation: Orientation{Rotation: 90},
+			width:       1080,
+			height:      1920,
+		},
+		{
+			name: "portrait",
+			stream: Stream{Width: 1920, Height: 1080, SideDataList: displayMatrixSideData(
+				"\n00000000:            0       65536           0\n"+
+					"00000001:       -65536           0           0\n"+
+					"00000002:            0           0  1073741824\n", -90)},
+			orientation: Orientation{Rotation: 90},
+			width:       1080,
+			height:      1920,
+		},
+		{
+			name: "upside down",
+			stream: Stream{Width: 1920, Height: 1080, SideDataList: displayMatrixSideData(
+				"\n00000000:       -65536           0           0\n"+
+					"00000001:            0      -65536           0\n"+
+					"00000002:            0           0  1073741824\n", 180)},
+			orientation: Orientation{Rotation: 180},
+			width:       1920,
+			height:      1080,
+		},
+		{
+			name: "mirrored portrait",
+			stream: Stream{Width: 1920, Height: 1080, SideDataList: displayMatrixSideData(
+				"\n00000000:            0       65536           0\n"+
+					"00000001:        65536           0           0\n"+
+					"00000002:            0           0  1073741824\n", -90)},
+			orientation: Orientation{Rotation: 90, HFlip: true},
+			width:       1080,
+			height:      1920,
+		},
+		{
+			name: "vertically mirrored",
+			stream: Stream{Width: 1920, Height: 1080, SideDataList: displayMatrixSideData(
+				"\n00000000:        65536           0           0\n"+
+					"00000001:            0      -65536           0\n"+
+					"00000002:            0           0  1073741824\n", 0)},
+			orientation: Orientation{VFlip: true},
+			width:       1920,
+			height:      1080,
+		},
+		{
+			name:        "rotation without matrix",
+			stream:      Stream{Width: 1920, Height: 1080, SideDataList: displayMatrixSideData("", 90)},
+			orientation: Orientation{Rotation: 270},
+			width:       1080,
+			height:      1920,
+		},
+		{
+			name:   "anamorphic",
+			stream: Stream{Width: 1440, Height: 1080, SampleAspectRatio: "4:3"},
+			width:  1920,
+			height: 1080,
+		},
+	}
+	for _, test := range tests {
+		if orientation := test.stream.Orientation(); orientation != test.orientation {
+			t.Errorf("%s: expected orientation %+v, got %+v", test.name, test.orientation, orientation)
+		}
+		if w, h := test.stream.DisplayWidth(), test.stream.DisplayHeight(); w != test.width || h != test.height {
+			t.Errorf("%s: expected display size %dx%d, got %dx%d", test.name, test.width, test.height, w, h)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+// +build !windows
+
+package ffprobe
+
+import (
+	"errors"
+	"syscall"
+)
+
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE)
+}
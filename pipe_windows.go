@@ -0,0 +1,17 @@
+//go:build windows
+// +build windows
+
+package ffprobe
+
+import (
+	"errors"
+	"syscall"
+)
+
+const errorNoData = syscall.Errno(232)
+
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ERROR_BROKEN_PIPE) ||
+		errors.Is(err, errorNoData)
+}
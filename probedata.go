@@ -26,6 +26,12 @@ const (
 type ProbeData struct {
 	Streams []*Stream `json:"streams"`
 	Format  *Format   `json:"format"`
+
+	// Warnings are the lines ffprobe logged while probing, only collected when a LogLevel is set on the Prober
+	Warnings []LogEntry `json:"-"`
+
+	// Info describes how the data was produced, only set when CollectInfo is set on the Prober
+	Info *ProbeInfo `json:"-"`
 }
 
 // Format is a json data structure to represent formats
@@ -79,6 +85,8 @@ type Stream struct {
 	Level              int               `json:"level,omitempty"`
 	ColorRange         string            `json:"color_range,omitempty"`
 	ColorSpace         string            `json:"color_space,omitempty"`
+	ColorTransfer      string            `json:"color_transfer,omitempty"`
+	ColorPrimaries     string            `json:"color_primaries,omitempty"`
 	SampleFmt          string            `json:"sample_fmt,omitempty"`
 	SampleRate         string            `json:"sample_rate,omitempty"`
 	Channels           int               `json:"channels,omitempty"`
@@ -166,3 +174,39 @@ func (p *ProbeData) firstStream(streamType StreamType) *Stream {
 	}
 	return nil
 }
+
+// Clone returns a deep copy of the probe data
+func (p *ProbeData) Clone() *ProbeData {
+	if p == nil {
+		return nil
+	}
+
+	clone := *p
+	clone.Info = p.Info.clone()
+	if p.Warnings != nil {
+		clone.Warnings = append([]LogEntry(nil), p.Warnings...)
+	}
+	if p.Format != nil {
+		format := *p.Format
+		format.TagList = p.Format.TagList.clone()
+		if p.Format.Tags != nil {
+			tags := *p.Format.Tags
+			format.Tags = &tags
+		}
+		clone.Format = &format
+	}
+
+	if p.Streams != nil {
+		clone.Streams = make([]*Stream, len(p.Streams))
+		for i, s := range p.Streams {
+			if s == nil {
+				continue
+			}
+			stream := *s
+			stream.TagList = s.TagList.clone()
+			stream.SideDataList = s.SideDataList.clone()
+			clone.Streams[i] = &stream
+		}
+	}
+	return &clone
+}
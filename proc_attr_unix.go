@@ -4,9 +4,23 @@
 package ffprobe
 
 import (
+	"os"
 	"syscall"
 )
 
+// procAttributes places ffprobe in its own process group, so any process it spawns can be killed along with it
 func procAttributes() *syscall.SysProcAttr {
-	return nil
+	return &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+}
+
+// terminateProcess asks the process group of the given process to exit
+func terminateProcess(proc *os.Process) error {
+	return syscall.Kill(-proc.Pid, syscall.SIGTERM)
+}
+
+// killProcess kills the process group of the given process
+func killProcess(proc *os.Process) error {
+	return syscall.Kill(-proc.Pid, syscall.SIGKILL)
 }
@@ -4,6 +4,7 @@
 package ffprobe
 
 import (
+	"os"
 	"syscall"
 )
 
@@ -12,3 +13,13 @@ func procAttributes() *syscall.SysProcAttr {
 		HideWindow: true,
 	}
 }
+
+// terminateProcess kills the given process, as windows has no way to ask a console process to exit
+func terminateProcess(proc *os.Process) error {
+	return proc.Kill()
+}
+
+// killProcess kills the given process
+func killProcess(proc *os.Process) error {
+	return proc.Kill()
+}
@@ -0,0 +1,98 @@
+package ffprobe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// processAlive reports whether the process with the given pid exists and is not a zombie
+func processAlive(pid int) bool {
+	stat, err := ioutil.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	// The state follows the command name, which is enclosed in parentheses
+	idx := bytes.LastIndexByte(stat, ')')
+	if idx < 0 || idx+2 >= len(stat) {
+		return false
+	}
+	return stat[idx+2] != 'Z'
+}
+
+// runOrphanTest starts a fake ffprobe spawning a child process, cancels the context and checks that the child is gone
+func runOrphanTest(t *testing.T, prober *Prober, script string) {
+	t.Helper()
+
+	pidFile, err := ioutil.TempFile("", "ffprobe-child-pid")
+	if err != nil {
+		t.Fatalf("Error creating pid file: %v", err)
+	}
+	_ = pidFile.Close()
+	defer os.Remove(pidFile.Name())
+
+	defer useFakeFFProbe(t, strings.Replace(script, "PIDFILE", pidFile.Name(), -1))()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancelFn()
+
+	start := time.Now()
+	_, err = prober.ProbeURL(ctx, testPath)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected a deadline exceeded error for a cancelled probe, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Probe took too long to return after cancellation: %v", elapsed)
+	}
+
+	buf, err := ioutil.ReadFile(pidFile.Name())
+	if err != nil {
+		t.Fatalf("Error reading pid file: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(buf)))
+	if err != nil {
+		t.Fatalf("Error parsing child pid %q: %v", buf, err)
+	}
+
+	// Give the kernel a moment to deliver the signals
+	deadline := time.Now().Add(2 * time.Second)
+	for processAlive(pid) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if processAlive(pid) {
+		t.Errorf("Child process %d is still running", pid)
+	}
+}
+
+func Test_ProbeURL_KillsProcessTree(t *testing.T) {
+	runOrphanTest(t, &Prober{}, "sleep 30 > /dev/null 2>&1 &\necho $! > PIDFILE\nwait")
+}
+
+func Test_ProbeURL_KillGracePeriod(t *testing.T) {
+	// The child ignores SIGTERM, so it has to be killed after the grace period
+	runOrphanTest(t, &Prober{KillGracePeriod: 200 * time.Millisecond},
+		"(trap '' TERM; sleep 30) > /dev/null 2>&1 &\necho $! > PIDFILE\ntrap 'exit 0' TERM\nwait")
+}
+
+func Test_ProbeURL_TerminatedWithOutput(t *testing.T) {
+	// ffprobe exits cleanly on SIGTERM after printing valid output, which must not be mistaken for a result
+	defer useFakeFFProbe(t, "trap 'exit 0' TERM\ncat <<'EOF'\n"+fakeProbeOutput+"\nEOF\nsleep 30 &\nwait")()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancelFn()
+
+	data, err := (&Prober{KillGracePeriod: time.Second}).ProbeURL(ctx, testPath)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected a deadline exceeded error, got %v", err)
+	}
+	if data != nil {
+		t.Errorf("Expected no data for a terminated probe, got %+v", data)
+	}
+}
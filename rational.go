@@ -0,0 +1,71 @@
+package ffprobe
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidRational is returned when a rational number cannot be parsed
+var ErrInvalidRational = errors.New("invalid rational")
+
+// Rational is a rational number as printed by ffprobe, like "34000/50000"
+type Rational struct {
+	Num int64
+	Den int64
+}
+
+// ParseRational parses a rational number like "34000/50000", or a whole number like "25"
+func ParseRational(str string) (Rational, error) {
+	num, den := str, "1"
+	if i := strings.IndexAny(str, "/:"); i >= 0 {
+		num, den = str[:i], str[i+1:]
+	}
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil {
+		return Rational{}, ErrInvalidRational
+	}
+	d, err := strconv.ParseInt(den, 10, 64)
+	if err != nil {
+		return Rational{}, ErrInvalidRational
+	}
+	return Rational{Num: n, Den: d}, nil
+}
+
+// Float64 returns the value of the rational, or 0 when the denominator is 0
+func (r Rational) Float64() float64 {
+	if r.Den == 0 {
+		return 0
+	}
+	return float64(r.Num) / float64(r.Den)
+}
+
+// String returns the rational in the form ffprobe prints it, like "34000/50000"
+func (r Rational) String() string {
+	return strconv.FormatInt(r.Num, 10) + "/" + strconv.FormatInt(r.Den, 10)
+}
+
+// MarshalJSON marshals the rational as a string, like ffprobe prints it
+func (r Rational) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// UnmarshalJSON unmarshals a rational printed as a string like "34000/50000", or as a plain number
+func (r *Rational) UnmarshalJSON(b []byte) error {
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		var n int64
+		if err := json.Unmarshal(b, &n); err != nil {
+			return err
+		}
+		*r = Rational{Num: n, Den: 1}
+		return nil
+	}
+	rational, err := ParseRational(str)
+	if err != nil {
+		return err
+	}
+	*r = rational
+	return nil
+}
@@ -0,0 +1,119 @@
+package ffprobe
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// RetryPolicy configures retrying probes that failed with a transient error, like an HTTP 503 response or a reset
+// connection. Retries never extend beyond the deadline of the context. Failures are classified using the errors
+// ffprobe logs, so the LogLevel of the Prober is raised to at least error.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum amount of attempts, including the first one
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, defaults to 200ms
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum delay between attempts, defaults to 5s
+	MaxBackoff time.Duration
+	// Multiplier is the factor the delay grows with after every retry, defaults to 2
+	Multiplier float64
+	// Jitter is the fraction of every delay that is randomized, between 0 and 1
+	Jitter float64
+	// Retryable decides whether an error is transient, defaults to IsRetryableError
+	Retryable func(err error) bool
+}
+
+// retryableMessages are the parts of ffprobe error messages indicating a transient failure
+var retryableMessages = []string{
+	"Server returned 5",
+	"Connection reset by peer",
+	"Connection refused",
+	"Connection timed out",
+	"Operation timed out",
+	"Network is unreachable",
+	"Temporary failure in name resolution",
+	"Broken pipe",
+}
+
+// IsRetryableError returns whether the error of a probe indicates a transient failure, based on the error output of
+// ffprobe. Note that ffprobe only reports these errors when the log level is error or more verbose.
+func IsRetryableError(err error) bool {
+	var runErr *RunError
+	if !errors.As(err, &runErr) || runErr.ExitCode <= 0 {
+		// ffprobe did not run, was killed or did not fail at all
+		return false
+	}
+	for _, msg := range retryableMessages {
+		if strings.Contains(runErr.Stderr, msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before the given retry, starting at 1
+func (r *RetryPolicy) backoff(retry int) time.Duration {
+	initial, max, multiplier := r.InitialBackoff, r.MaxBackoff, r.Multiplier
+	if initial <= 0 {
+		initial = 200 * time.Millisecond
+	}
+	if max <= 0 {
+		max = 5 * time.Second
+	}
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	delay := float64(initial)
+	for i := 1; i < retry && delay < float64(max); i++ {
+		delay *= multiplier
+	}
+	if delay > float64(max) {
+		delay = float64(max)
+	}
+	if r.Jitter > 0 {
+		jitter := r.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		delay -= delay * jitter * rand.Float64() //nolint:gosec
+	}
+	return time.Duration(delay)
+}
+
+func (r *RetryPolicy) retryable(err error) bool {
+	if r.Retryable != nil {
+		return r.Retryable(err)
+	}
+	return IsRetryableError(err)
+}
+
+// runProbeWithRetry runs the probe, retrying transient failures according to the retry policy of the Prober
+func (p *Prober) runProbeWithRetry(ctx context.Context, args []string) (*ProbeData, error) {
+	data, err := p.runProbe(ctx, args, nil)
+	if p.Retry == nil {
+		return data, err
+	}
+
+	for attempt := 2; attempt <= p.Retry.MaxAttempts && err != nil && p.Retry.retryable(err); attempt++ {
+		delay := p.Retry.backoff(attempt - 1)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			// There is no time left for another attempt
+			return data, err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return data, err
+		case <-timer.C:
+		}
+
+		data, err = p.runProbe(ctx, args, nil)
+	}
+	return data, err
+}
@@ -0,0 +1,76 @@
+package ffprobe
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_IsRetryableError(t *testing.T) {
+	tests := []struct {
+		err       error
+		retryable bool
+	}{
+		{&RunError{ExitCode: 1, Stderr: "http://host/video.mp4: Server returned 503 Service Unavailable"}, true},
+		{&RunError{ExitCode: 1, Stderr: "http://host/video.mp4: Server returned 5XX Server Error reply"}, true},
+		{&RunError{ExitCode: 1, Stderr: "http://host/video.mp4: Connection reset by peer"}, true},
+		{&RunError{ExitCode: 1, Stderr: "http://host/video.mp4: Server returned 404 Not Found"}, false},
+		{&RunError{ExitCode: 1, Stderr: "video.mp4: Invalid data found when processing input"}, false},
+		{&RunError{ExitCode: -1, Stderr: "Connection reset by peer"}, false},
+		{errors.New("Connection reset by peer"), false},
+		{context.DeadlineExceeded, false},
+	}
+
+	for _, tt := range tests {
+		if IsRetryableError(tt.err) != tt.retryable {
+			t.Errorf("Expected retryable %v for %v", tt.retryable, tt.err)
+		}
+	}
+}
+
+func Test_RetryPolicy_Backoff(t *testing.T) {
+	policy := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+		800 * time.Millisecond, time.Second, time.Second}
+	for i, delay := range expected {
+		if backoff := policy.backoff(i + 1); backoff != delay {
+			t.Errorf("Expected backoff %v for retry %d, got %v", delay, i+1, backoff)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if backoff := policy.backoff(1); backoff < 50*time.Millisecond || backoff > 100*time.Millisecond {
+			t.Errorf("Backoff %v out of jitter range", backoff)
+		}
+	}
+}
+
+func Test_ProbeURL_Retry(t *testing.T) {
+	ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFn()
+
+	var requests int32
+	fileServer := http.FileServer(http.Dir("./assets"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first two requests
+		if atomic.AddInt32(&requests, 1) <= 2 {
+			http.Error(w, "try again later", http.StatusServiceUnavailable)
+			return
+		}
+		fileServer.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	prober := &Prober{Retry: &RetryPolicy{MaxAttempts: 3, InitialBackoff: 10 * time.Millisecond}}
+	data, err := prober.ProbeURL(ctx, server.URL+"/test.mp4")
+	if err != nil {
+		t.Fatalf("Error getting data: %v", err)
+	}
+
+	validateData(t, data)
+}
@@ -0,0 +1,71 @@
+//go:build !windows
+// +build !windows
+
+package ffprobe
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_ProbeURL_RetryAttempts(t *testing.T) {
+	countFile, err := ioutil.TempFile("", "ffprobe-count")
+	if err != nil {
+		t.Fatalf("Error creating count file: %v", err)
+	}
+	_ = countFile.Close()
+	defer os.Remove(countFile.Name())
+
+	// Fail transiently for the first two attempts
+	defer useFakeFFProbe(t, "echo x >> "+countFile.Name()+"\n"+
+		"if [ $(wc -l < "+countFile.Name()+") -le 2 ]; then echo 'Server returned 503 Service Unavailable' >&2; exit 1; fi\n"+
+		"echo '"+fakeProbeOutput+"'")()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFn()
+
+	attempts := func() int {
+		buf, _ := ioutil.ReadFile(countFile.Name())
+		return strings.Count(string(buf), "x")
+	}
+
+	// Too few attempts
+	prober := &Prober{Retry: &RetryPolicy{MaxAttempts: 2, InitialBackoff: 10 * time.Millisecond}}
+	_, err = prober.ProbeURL(ctx, testPath)
+	if !IsRetryableError(err) {
+		t.Errorf("Expected a transient error, got %v", err)
+	}
+	if n := attempts(); n != 2 {
+		t.Errorf("Expected 2 attempts, got %d", n)
+	}
+
+	// The third attempt succeeds
+	_, err = prober.ProbeURL(ctx, testPath)
+	if err != nil {
+		t.Errorf("Error getting data: %v", err)
+	}
+	if n := attempts(); n != 3 {
+		t.Errorf("Expected 3 attempts, got %d", n)
+	}
+}
+
+func Test_ProbeURL_RetryDeadline(t *testing.T) {
+	defer useFakeFFProbe(t, "echo 'Connection refused' >&2\nexit 1")()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancelFn()
+
+	start := time.Now()
+	prober := &Prober{Retry: &RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Second}}
+	_, err := prober.ProbeURL(ctx, testPath)
+	if !IsRetryableError(err) {
+		t.Errorf("Expected a transient error, got %v", err)
+	}
+	if time.Since(start) > 400*time.Millisecond {
+		t.Errorf("Expected no retry beyond the deadline")
+	}
+}
@@ -3,6 +3,8 @@ package ffprobe
 import (
 	"encoding/json"
 	"errors"
+	"reflect"
+	"sync"
 )
 
 var (
@@ -21,6 +23,19 @@ const (
 	SideDataTypeSkipSamples              = "Skip Samples"
 	SideDataTypeMasteringDisplayMetadata = "Mastering display metadata"
 	SideDataTypeContentLightLevel        = "Content light level metadata"
+	SideDataTypeDOVIConfig               = "DOVI configuration record"
+	SideDataTypeHDR10Plus                = "HDR10+ Dynamic Metadata (SMPTE 2094-40)"
+	SideDataTypeHDR10PlusFrame           = "HDR Dynamic Metadata SMPTE2094-40 (HDR10+)"
+	SideDataTypeReplayGain               = "Replay Gain"
+	SideDataTypeDownmixInfo              = "Metadata relevant to a downmix procedure"
+	SideDataTypeAudioServiceType         = "Audio Service Type"
+	SideDataTypeCPBProperties            = "CPB properties"
+	SideDataTypeEncryptionInitInfo       = "Encryption initialization data"
+	SideDataTypeEncryptionInfo           = "Encryption info"
+	SideDataTypeActiveFormatDescription  = "Active Format Description data"
+	SideDataTypeICCProfile               = "ICC Profile"
+	SideDataTypeFilmGrainParams          = "Film grain parameters"
+	SideDataTypeAmbientViewingEnv        = "Ambient viewing environment"
 )
 
 type SideDataBase struct {
@@ -65,18 +80,19 @@ type SideDataSkipSamples struct {
 }
 
 // SideDataMasteringDisplayMetadata represents the mastering display metadata side data.
+// The chromaticity coordinates and luminance are printed by ffprobe as rationals.
 type SideDataMasteringDisplayMetadata struct {
 	SideDataBase
-	RedX         int `json:"red_x,omitempty"`
-	RedY         int `json:"red_y,omitempty"`
-	GreenX       int `json:"green_x,omitempty"`
-	GreenY       int `json:"green_y,omitempty"`
-	BlueX        int `json:"blue_x,omitempty"`
-	BlueY        int `json:"blue_y,omitempty"`
-	WhitePointX  int `json:"white_point_x,omitempty"`
-	WhitePointY  int `json:"white_point_y,omitempty"`
-	MinLuminance int `json:"min_luminance,omitempty"`
-	MaxLuminance int `json:"max_luminance,omitempty"`
+	RedX         Rational `json:"red_x"`
+	RedY         Rational `json:"red_y"`
+	GreenX       Rational `json:"green_x"`
+	GreenY       Rational `json:"green_y"`
+	BlueX        Rational `json:"blue_x"`
+	BlueY        Rational `json:"blue_y"`
+	WhitePointX  Rational `json:"white_point_x"`
+	WhitePointY  Rational `json:"white_point_y"`
+	MinLuminance Rational `json:"min_luminance"`
+	MaxLuminance Rational `json:"max_luminance"`
 }
 
 // SideDataContentLightLevel represents the content light level side data.
@@ -86,9 +102,158 @@ type SideDataContentLightLevel struct {
 	MaxAverage int `json:"max_average,omitempty"`
 }
 
-// SideDataUnknown represents an unknown side data.
+// SideDataDOVIConfig represents the Dolby Vision configuration record side data.
+type SideDataDOVIConfig struct {
+	SideDataBase
+	DVVersionMajor            int `json:"dv_version_major"`
+	DVVersionMinor            int `json:"dv_version_minor"`
+	DVProfile                 int `json:"dv_profile"`
+	DVLevel                   int `json:"dv_level"`
+	RPUPresentFlag            int `json:"rpu_present_flag"`
+	ELPresentFlag             int `json:"el_present_flag"`
+	BLPresentFlag             int `json:"bl_present_flag"`
+	DVBLSignalCompatibilityID int `json:"dv_bl_signal_compatibility_id"`
+}
+
+// SideDataReplayGain represents the ReplayGain side data. Gains are in dB, peaks are relative to full scale.
+type SideDataReplayGain struct {
+	SideDataBase
+	TrackGain OptionalFloat `json:"track_gain"`
+	TrackPeak OptionalFloat `json:"track_peak"`
+	AlbumGain OptionalFloat `json:"album_gain"`
+	AlbumPeak OptionalFloat `json:"album_peak"`
+}
+
+// SideDataDownmixInfo represents the downmix info side data, which only exists as frame side data.
+// Mix levels are linear gains.
+type SideDataDownmixInfo struct {
+	SideDataBase
+	PreferredDownmixType DownmixType   `json:"preferred_downmix_type"`
+	CenterMixLevel       OptionalFloat `json:"center_mix_level"`
+	CenterMixLevelLtRt   OptionalFloat `json:"center_mix_level_ltrt"`
+	SurroundMixLevel     OptionalFloat `json:"surround_mix_level"`
+	SurroundMixLevelLtRt OptionalFloat `json:"surround_mix_level_ltrt"`
+	LFEMixLevel          OptionalFloat `json:"lfe_mix_level"`
+}
+
+// SideDataAudioServiceType represents the audio service type side data.
+type SideDataAudioServiceType struct {
+	SideDataBase
+	ServiceType AudioServiceType `json:"service_type"`
+}
+
+// SideDataCPBProperties represents the coded picture buffer properties side data.
+// Bitrates are in bits per second and the buffer size in bits. The VBV delay is in periods of a 27 MHz clock, -1 when
+// it is unknown.
+type SideDataCPBProperties struct {
+	SideDataBase
+	MaxBitrate int64 `json:"max_bitrate"`
+	MinBitrate int64 `json:"min_bitrate"`
+	AvgBitrate int64 `json:"avg_bitrate"`
+	BufferSize int64 `json:"buffer_size"`
+	VBVDelay   int64 `json:"vbv_delay"`
+}
+
+// SideDataEncryptionInitInfo represents the encryption initialization data side data.
+// ffprobe prints no fields for it, its presence means the stream is encrypted.
+type SideDataEncryptionInitInfo struct {
+	SideDataBase
+}
+
+// SideDataEncryptionInfo represents the encryption info side data.
+// ffprobe prints no fields for it, its presence means the stream is encrypted.
+type SideDataEncryptionInfo struct {
+	SideDataBase
+}
+
+// SideDataActiveFormatDescription represents the active format description side data.
+type SideDataActiveFormatDescription struct {
+	SideDataBase
+	ActiveFormat int `json:"active_format"`
+}
+
+// SideDataICCProfile represents the ICC profile side data.
+type SideDataICCProfile struct {
+	SideDataBase
+	Name string `json:"name,omitempty"`
+	Size int    `json:"size,omitempty"`
+}
+
+// SideDataFilmGrainParams represents the film grain parameters side data.
+type SideDataFilmGrainParams struct {
+	SideDataBase
+	Type string `json:"type"`
+	Seed uint64 `json:"seed"`
+}
+
+// SideDataAmbientViewingEnv represents the ambient viewing environment side data.
+// The illuminance is in lux, the light chromaticity in CIE 1931 xy coordinates.
+type SideDataAmbientViewingEnv struct {
+	SideDataBase
+	AmbientIlluminance Rational `json:"ambient_illuminance"`
+	AmbientLightX      Rational `json:"ambient_light_x"`
+	AmbientLightY      Rational `json:"ambient_light_y"`
+}
+
+// SideDataUnknown represents an unknown side data, or side data whose fields do not fit its registered type.
 type SideDataUnknown Tags
 
+var (
+	sideDataTypesMu sync.RWMutex
+	sideDataTypes   = make(map[string]func() interface{})
+)
+
+func init() {
+	RegisterSideDataType(SideDataTypeDisplayMatrix, func() interface{} { return new(SideDataDisplayMatrix) })
+	RegisterSideDataType(SideDataTypeStereo3D, func() interface{} { return new(SideDataStereo3D) })
+	RegisterSideDataType(SideDataTypeSphericalMapping, func() interface{} { return new(SideDataSphericalMapping) })
+	RegisterSideDataType(SideDataTypeSkipSamples, func() interface{} { return new(SideDataSkipSamples) })
+	RegisterSideDataType(SideDataTypeMasteringDisplayMetadata, func() interface{} {
+		return new(SideDataMasteringDisplayMetadata)
+	})
+	RegisterSideDataType(SideDataTypeContentLightLevel, func() interface{} { return new(SideDataContentLightLevel) })
+	RegisterSideDataType(SideDataTypeDOVIConfig, func() interface{} { return new(SideDataDOVIConfig) })
+	RegisterSideDataType(SideDataTypeReplayGain, func() interface{} { return new(SideDataReplayGain) })
+	RegisterSideDataType(SideDataTypeDownmixInfo, func() interface{} { return new(SideDataDownmixInfo) })
+	RegisterSideDataType(SideDataTypeAudioServiceType, func() interface{} { return new(SideDataAudioServiceType) })
+	RegisterSideDataType(SideDataTypeCPBProperties, func() interface{} { return new(SideDataCPBProperties) })
+	RegisterSideDataType(SideDataTypeEncryptionInitInfo, func() interface{} { return new(SideDataEncryptionInitInfo) })
+	RegisterSideDataType(SideDataTypeEncryptionInfo, func() interface{} { return new(SideDataEncryptionInfo) })
+	RegisterSideDataType(SideDataTypeActiveFormatDescription, func() interface{} {
+		return new(SideDataActiveFormatDescription)
+	})
+	RegisterSideDataType(SideDataTypeICCProfile, func() interface{} { return new(SideDataICCProfile) })
+	RegisterSideDataType(SideDataTypeFilmGrainParams, func() interface{} { return new(SideDataFilmGrainParams) })
+	RegisterSideDataType(SideDataTypeAmbientViewingEnv, func() interface{} { return new(SideDataAmbientViewingEnv) })
+}
+
+// RegisterSideDataType registers the factory for the typed data of a side data type, which is consulted when
+// unmarshalling side data. Registering a side data type that is already registered replaces its factory, which also
+// works for the built-in types. Side data of unregistered types is unmarshalled into SideDataUnknown.
+//
+// The factory must return a new pointer to a struct that the side data JSON can be unmarshalled into. The struct
+// should embed SideDataBase, so the side data type is kept when marshalling it again. Cloned probe data shares any
+// maps, slices or pointers within the struct with the original.
+func RegisterSideDataType(name string, factory func() interface{}) {
+	if name == "" || factory == nil {
+		panic("ffprobe: RegisterSideDataType called with an empty name or nil factory")
+	}
+	sideDataTypesMu.Lock()
+	defer sideDataTypesMu.Unlock()
+	sideDataTypes[name] = factory
+}
+
+// newSideData returns new typed data for the side data type from the registry
+func newSideData(name string) interface{} {
+	sideDataTypesMu.RLock()
+	factory, ok := sideDataTypes[name]
+	sideDataTypesMu.RUnlock()
+	if !ok {
+		return new(SideDataUnknown)
+	}
+	return factory()
+}
+
 // SideData represents a side data packet.
 type SideData struct {
 	SideDataBase
@@ -107,24 +272,16 @@ func (sd *SideData) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
-	switch sd.Type {
-	case SideDataTypeDisplayMatrix:
-		sd.Data = &SideDataDisplayMatrix{}
-	case SideDataTypeStereo3D:
-		sd.Data = new(SideDataStereo3D)
-	case SideDataTypeSphericalMapping:
-		sd.Data = new(SideDataSphericalMapping)
-	case SideDataTypeSkipSamples:
-		sd.Data = new(SideDataSkipSamples)
-	case SideDataTypeMasteringDisplayMetadata:
-		sd.Data = new(SideDataMasteringDisplayMetadata)
-	case SideDataTypeContentLightLevel:
-		sd.Data = new(SideDataContentLightLevel)
-	default:
-		sd.Data = new(SideDataUnknown)
+	sd.Data = newSideData(sd.Type)
+	if err := json.Unmarshal(b, sd.Data); err != nil {
+		// Unexpected values, like a negative number for an unsigned field, must not fail the whole probe
+		unknown := new(SideDataUnknown)
+		if json.Unmarshal(b, unknown) != nil {
+			return err
+		}
+		sd.Data = unknown
 	}
-
-	return json.Unmarshal(b, sd.Data)
+	return nil
 }
 
 func (sd *SideData) MarshalJSON() ([]byte, error) {
@@ -152,6 +309,33 @@ func (s *SideDataList) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// clone returns a deep copy of the side data list
+func (s SideDataList) clone() SideDataList {
+	if s == nil {
+		return nil
+	}
+
+	clone := make(SideDataList, len(s))
+	for i, sd := range s {
+		clone[i] = sd
+		switch data := sd.Data.(type) {
+		case nil:
+		case *SideDataUnknown:
+			unknown := SideDataUnknown(Tags(*data).clone())
+			clone[i].Data = &unknown
+		default:
+			// Typed side data are pointers to structs, the built-in types have no reference types
+			val := reflect.ValueOf(data)
+			if val.Kind() == reflect.Ptr && !val.IsNil() {
+				copied := reflect.New(val.Elem().Type())
+				copied.Elem().Set(val.Elem())
+				clone[i].Data = copied.Interface()
+			}
+		}
+	}
+	return clone
+}
+
 // FindUnknownSideData searches for SideData of type SideDataUnknown in the SideDataList.
 // If such SideData is found, it is returned, otherwise, an error is returned
 // indicating that the SideData of type SideDataUnknown was not found or the found
@@ -182,6 +366,32 @@ func (s SideDataList) FindSideData(sideDataType string) (interface{}, error) {
 	return data, nil
 }
 
+// FindSideDataAs searches for SideData by its type in the SideDataList and stores it in the value target points to,
+// which must be a non-nil pointer to a pointer of the registered type, much like errors.As:
+//
+//	var matrix *ffprobe.SideDataDisplayMatrix
+//	err := stream.SideDataList.FindSideDataAs(ffprobe.SideDataTypeDisplayMatrix, &matrix)
+//
+// ErrSideDataNotFound is returned when the SideData is not found, ErrSideDataUnexpectedType when its data is not
+// assignable to the target.
+func (s SideDataList) FindSideDataAs(sideDataType string, target interface{}) error {
+	val := reflect.ValueOf(target)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		panic("ffprobe: FindSideDataAs target must be a non-nil pointer")
+	}
+
+	data, found := s.findSideDataByName(sideDataType)
+	if !found {
+		return ErrSideDataNotFound
+	}
+	dataVal := reflect.ValueOf(data)
+	if data == nil || !dataVal.Type().AssignableTo(val.Elem().Type()) {
+		return ErrSideDataUnexpectedType
+	}
+	val.Elem().Set(dataVal)
+	return nil
+}
+
 // GetDisplayMatrix retrieves the DisplayMatrix from the SideData. If the DisplayMatrix is not found or
 // the SideData is of the wrong type, an error is returned.
 func (s SideDataList) GetDisplayMatrix() (*SideDataDisplayMatrix, error) {
@@ -266,6 +476,167 @@ func (s SideDataList) GetContentLightLevel() (*SideDataContentLightLevel, error)
 	return contentLightLevel, nil
 }
 
+// GetDOVIConfig retrieves the Dolby Vision configuration record from the SideData. If the configuration record is not
+// found or the SideData is of the wrong type, an error is returned.
+func (s SideDataList) GetDOVIConfig() (*SideDataDOVIConfig, error) {
+	data, found := s.findSideDataByName(SideDataTypeDOVIConfig)
+	if !found {
+		return nil, ErrSideDataNotFound
+	}
+	doviConfig, ok := data.(*SideDataDOVIConfig)
+	if !ok {
+		return nil, ErrSideDataUnexpectedType
+	}
+	return doviConfig, nil
+}
+
+// GetReplayGain retrieves the ReplayGain data from the SideData. If the ReplayGain data is not found or
+// the SideData is of the wrong type, an error is returned.
+func (s SideDataList) GetReplayGain() (*SideDataReplayGain, error) {
+	data, found := s.findSideDataByName(SideDataTypeReplayGain)
+	if !found {
+		return nil, ErrSideDataNotFound
+	}
+	replayGain, ok := data.(*SideDataReplayGain)
+	if !ok {
+		return nil, ErrSideDataUnexpectedType
+	}
+	return replayGain, nil
+}
+
+// GetDownmixInfo retrieves the DownmixInfo data from the SideData. If the DownmixInfo data is not found or
+// the SideData is of the wrong type, an error is returned.
+func (s SideDataList) GetDownmixInfo() (*SideDataDownmixInfo, error) {
+	data, found := s.findSideDataByName(SideDataTypeDownmixInfo)
+	if !found {
+		return nil, ErrSideDataNotFound
+	}
+	downmixInfo, ok := data.(*SideDataDownmixInfo)
+	if !ok {
+		return nil, ErrSideDataUnexpectedType
+	}
+	return downmixInfo, nil
+}
+
+// GetAudioServiceType retrieves the AudioServiceType data from the SideData. If the AudioServiceType data is not found or
+// the SideData is of the wrong type, an error is returned.
+func (s SideDataList) GetAudioServiceType() (*SideDataAudioServiceType, error) {
+	data, found := s.findSideDataByName(SideDataTypeAudioServiceType)
+	if !found {
+		return nil, ErrSideDataNotFound
+	}
+	audioServiceType, ok := data.(*SideDataAudioServiceType)
+	if !ok {
+		return nil, ErrSideDataUnexpectedType
+	}
+	return audioServiceType, nil
+}
+
+// GetCPBProperties retrieves the CPBProperties data from the SideData. If the CPBProperties data is not found or
+// the SideData is of the wrong type, an error is returned.
+func (s SideDataList) GetCPBProperties() (*SideDataCPBProperties, error) {
+	data, found := s.findSideDataByName(SideDataTypeCPBProperties)
+	if !found {
+		return nil, ErrSideDataNotFound
+	}
+	cpbProperties, ok := data.(*SideDataCPBProperties)
+	if !ok {
+		return nil, ErrSideDataUnexpectedType
+	}
+	return cpbProperties, nil
+}
+
+// GetEncryptionInitInfo retrieves the EncryptionInitInfo data from the SideData. If the EncryptionInitInfo data is not found or
+// the SideData is of the wrong type, an error is returned.
+func (s SideDataList) GetEncryptionInitInfo() (*SideDataEncryptionInitInfo, error) {
+	data, found := s.findSideDataByName(SideDataTypeEncryptionInitInfo)
+	if !found {
+		return nil, ErrSideDataNotFound
+	}
+	encryptionInitInfo, ok := data.(*SideDataEncryptionInitInfo)
+	if !ok {
+		return nil, ErrSideDataUnexpectedType
+	}
+	return encryptionInitInfo, nil
+}
+
+// GetEncryptionInfo retrieves the EncryptionInfo data from the SideData. If the EncryptionInfo data is not found or
+// the SideData is of the wrong type, an error is returned.
+func (s SideDataList) GetEncryptionInfo() (*SideDataEncryptionInfo, error) {
+	data, found := s.findSideDataByName(SideDataTypeEncryptionInfo)
+	if !found {
+		return nil, ErrSideDataNotFound
+	}
+	encryptionInfo, ok := data.(*SideDataEncryptionInfo)
+	if !ok {
+		return nil, ErrSideDataUnexpectedType
+	}
+	return encryptionInfo, nil
+}
+
+// GetActiveFormatDescription retrieves the ActiveFormatDescription data from the SideData. If the ActiveFormatDescription data is not found or
+// the SideData is of the wrong type, an error is returned.
+func (s SideDataList) GetActiveFormatDescription() (*SideDataActiveFormatDescription, error) {
+	data, found := s.findSideDataByName(SideDataTypeActiveFormatDescription)
+	if !found {
+		return nil, ErrSideDataNotFound
+	}
+	activeFormatDescription, ok := data.(*SideDataActiveFormatDescription)
+	if !ok {
+		return nil, ErrSideDataUnexpectedType
+	}
+	return activeFormatDescription, nil
+}
+
+// GetICCProfile retrieves the ICCProfile data from the SideData. If the ICCProfile data is not found or
+// the SideData is of the wrong type, an error is returned.
+func (s SideDataList) GetICCProfile() (*SideDataICCProfile, error) {
+	data, found := s.findSideDataByName(SideDataTypeICCProfile)
+	if !found {
+		return nil, ErrSideDataNotFound
+	}
+	iccProfile, ok := data.(*SideDataICCProfile)
+	if !ok {
+		return nil, ErrSideDataUnexpectedType
+	}
+	return iccProfile, nil
+}
+
+// GetFilmGrainParams retrieves the FilmGrainParams data from the SideData. If the FilmGrainParams data is not found or
+// the SideData is of the wrong type, an error is returned.
+func (s SideDataList) GetFilmGrainParams() (*SideDataFilmGrainParams, error) {
+	data, found := s.findSideDataByName(SideDataTypeFilmGrainParams)
+	if !found {
+		return nil, ErrSideDataNotFound
+	}
+	filmGrainParams, ok := data.(*SideDataFilmGrainParams)
+	if !ok {
+		return nil, ErrSideDataUnexpectedType
+	}
+	return filmGrainParams, nil
+}
+
+// GetAmbientViewingEnv retrieves the AmbientViewingEnv data from the SideData. If the AmbientViewingEnv data is not found or
+// the SideData is of the wrong type, an error is returned.
+func (s SideDataList) GetAmbientViewingEnv() (*SideDataAmbientViewingEnv, error) {
+	data, found := s.findSideDataByName(SideDataTypeAmbientViewingEnv)
+	if !found {
+		return nil, ErrSideDataNotFound
+	}
+	ambientViewingEnv, ok := data.(*SideDataAmbientViewingEnv)
+	if !ok {
+		return nil, ErrSideDataUnexpectedType
+	}
+	return ambientViewingEnv, nil
+}
+
+// IsEncrypted returns whether the SideData contains encryption initialization data or encryption info
+func (s SideDataList) IsEncrypted() bool {
+	_, initFound := s.findSideDataByName(SideDataTypeEncryptionInitInfo)
+	_, infoFound := s.findSideDataByName(SideDataTypeEncryptionInfo)
+	return initFound || infoFound
+}
+
 func (s SideDataList) findSideDataByName(sideDataType string) (interface{}, bool) {
 	for _, sd := range s {
 		if sd.Type == sideDataType {
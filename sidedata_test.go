@@ -0,0 +1,168 @@
+package ffprobe
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func Test_CodecSideData(t *testing.T) {
+	var list SideDataList
+	err := json.Unmarshal([]byte(`[
+		{
+			"side_data_type": "CPB properties",
+			"max_bitrate": 8000000,
+			"min_bitrate": 0,
+			"avg_bitrate": 5000000,
+			"buffer_size": 16000000,
+			"vbv_delay": -1
+		},
+		{
+			"side_data_type": "Encryption initialization data"
+		},
+		{
+			"side_data_type": "Active Format Description data",
+			"active_format": 10
+		},
+		{
+			"side_data_type": "ICC Profile",
+			"name": "Display P3",
+			"size": 536
+		},
+		{
+			"side_data_type": "Film grain parameters",
+			"type": "av1",
+			"seed": 7391
+		},
+		{
+			"side_data_type": "Ambient viewing environment",
+			"ambient_illuminance": "314/10000",
+			"ambient_light_x": "15635/50000",
+			"ambient_light_y": "16450/50000"
+		}
+	]`), &list)
+	if err != nil {
+		t.Fatalf("Error unmarshalling side data: %v", err)
+	}
+
+	cpb, err := list.GetCPBProperties()
+	if err != nil {
+		t.Fatalf("Error getting CPB properties: %v", err)
+	}
+	if cpb.MaxBitrate != 8000000 || cpb.AvgBitrate != 5000000 || cpb.BufferSize != 16000000 || cpb.VBVDelay != -1 {
+		t.Errorf("Unexpected CPB properties %+v", cpb)
+	}
+
+	if !list.IsEncrypted() {
+		t.Errorf("Expected side data to be encrypted")
+	}
+	if _, err := list.GetEncryptionInitInfo(); err != nil {
+		t.Errorf("Error getting encryption initialization data: %v", err)
+	}
+	if _, err := list.GetEncryptionInfo(); err != ErrSideDataNotFound {
+		t.Errorf("Expected ErrSideDataNotFound for encryption info, got %v", err)
+	}
+
+	afd, err := list.GetActiveFormatDescription()
+	if err != nil || afd.ActiveFormat != 10 {
+		t.Errorf("Expected active format 10, got %+v (%v)", afd, err)
+	}
+	icc, err := list.GetICCProfile()
+	if err != nil || icc.Name != "Display P3" || icc.Size != 536 {
+		t.Errorf("Expected Display P3 ICC profile, got %+v (%v)", icc, err)
+	}
+	filmGrain, err := list.GetFilmGrainParams()
+	if err != nil || filmGrain.Type != "av1" || filmGrain.Seed != 7391 {
+		t.Errorf("Expected AV1 film grain, got %+v (%v)", filmGrain, err)
+	}
+
+	ambient, err := list.GetAmbientViewingEnv()
+	if err != nil {
+		t.Fatalf("Error getting ambient viewing environment: %v", err)
+	}
+	if ambient.Illuminance() != 0.0314 || ambient.LightChromaticity() != (Chromaticity{X: 0.3127, Y: 0.329}) {
+		t.Errorf("Unexpected ambient viewing environment %v lux at %v",
+			ambient.Illuminance(), ambient.LightChromaticity())
+	}
+}
+
+func Test_SideData_UnexpectedValues(t *testing.T) {
+	var list SideDataList
+	err := json.Unmarshal([]byte(`[
+		{"side_data_type": "Film grain parameters", "type": "av1", "seed": -1},
+		{"side_data_type": "ICC Profile", "name": "Display P3", "size": 536}
+	]`), &list)
+	if err != nil {
+		t.Fatalf("Error unmarshalling side data: %v", err)
+	}
+
+	if _, err := list.GetFilmGrainParams(); err != ErrSideDataUnexpectedType {
+		t.Errorf("Expected ErrSideDataUnexpectedType for film grain with a negative seed, got %v", err)
+	}
+	var unknown *SideDataUnknown
+	if err := list.FindSideDataAs(SideDataTypeFilmGrainParams, &unknown); err != nil || (*unknown)["seed"] != -1.0 {
+		t.Errorf("Expected the raw film grain fields, got %v (%v)", unknown, err)
+	}
+	if icc, err := list.GetICCProfile(); err != nil || icc.Name != "Display P3" {
+		t.Errorf("Expected Display P3 ICC profile, got %+v (%v)", icc, err)
+	}
+}
+
+type testSideData struct {
+	SideDataBase
+	Answer int `json:"answer"`
+}
+
+// restoreSideDataType returns a function restoring the current registration of the side data type
+func restoreSideDataType(name string) func() {
+	sideDataTypesMu.RLock()
+	factory, ok := sideDataTypes[name]
+	sideDataTypesMu.RUnlock()
+
+	return func() {
+		sideDataTypesMu.Lock()
+		defer sideDataTypesMu.Unlock()
+		if ok {
+			sideDataTypes[name] = factory
+		} else {
+			delete(sideDataTypes, name)
+		}
+	}
+}
+
+func Test_RegisterSideDataType(t *testing.T) {
+	defer restoreSideDataType("Test side data")()
+	RegisterSideDataType("Test side data", func() interface{} { return new(testSideData) })
+
+	var list SideDataList
+	err := json.Unmarshal([]byte(`[
+		{"side_data_type": "Test side data", "answer": 42},
+		{"side_data_type": "Display Matrix", "displaymatrix": "", "rotation": -90}
+	]`), &list)
+	if err != nil {
+		t.Fatalf("Error unmarshalling side data: %v", err)
+	}
+
+	var custom *testSideData
+	if err := list.FindSideDataAs("Test side data", &custom); err != nil {
+		t.Fatalf("Error finding registered side data: %v", err)
+	}
+	if custom.Answer != 42 || custom.Type != "Test side data" {
+		t.Errorf("Expected answer 42, got %+v", custom)
+	}
+
+	var matrix *SideDataDisplayMatrix
+	if err := list.FindSideDataAs(SideDataTypeDisplayMatrix, &matrix); err != nil || matrix.Rotation != -90 {
+		t.Errorf("Expected display matrix with rotation -90, got %+v (%v)", matrix, err)
+	}
+	if err := list.FindSideDataAs("Test side data", &matrix); err != ErrSideDataUnexpectedType {
+		t.Errorf("Expected ErrSideDataUnexpectedType, got %v", err)
+	}
+	if err := list.FindSideDataAs(SideDataTypeStereo3D, &matrix); err != ErrSideDataNotFound {
+		t.Errorf("Expected ErrSideDataNotFound, got %v", err)
+	}
+
+	var data interface{}
+	if err := list.FindSideDataAs("Test side data", &data); err != nil || data != custom {
+		t.Errorf("Expected the side data in an interface, got %v (%v)", data, err)
+	}
+}
@@ -12,6 +12,18 @@ var ErrTagNotFound = errors.New("tag not found")
 // Tags is the map of tag names to values
 type Tags map[string]interface{}
 
+// clone returns a copy of the tags
+func (t Tags) clone() Tags {
+	if t == nil {
+		return nil
+	}
+	clone := make(Tags, len(t))
+	for k, v := range t {
+		clone[k] = v
+	}
+	return clone
+}
+
 // GetInt returns a tag value as int64 and an error if one occurred.
 // ErrTagNotFound will be returned if the key can't be found, ParseError if
 // a parsing error occurs.
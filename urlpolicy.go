@@ -0,0 +1,311 @@
+package ffprobe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrURLNotAllowed is returned when a URL is rejected by the URLPolicy of a Prober
+var ErrURLNotAllowed = errors.New("url not allowed")
+
+// DefaultAllowedSchemes are the URL schemes allowed when a URLPolicy does not specify any
+var DefaultAllowedSchemes = []string{"http", "https"}
+
+// DefaultSafeFormats are the demuxers allowed when a URLPolicy does not allow nested resources. None of these
+// demuxers open other resources than the input itself, unlike for example the hls, dash, concat and image2 demuxers.
+var DefaultSafeFormats = []string{
+	"mov", "mp4", "matroska", "webm", "avi", "flv", "asf", "mpegts", "mpeg", "mpegvideo", "mxf", "ogg",
+	"wav", "w64", "aiff", "caf", "mp3", "aac", "ac3", "eac3", "dts", "truehd", "flac", "amr",
+	"h264", "hevc", "m4v", "ivf", "srt", "ass", "webvtt",
+	"png_pipe", "jpeg_pipe", "webp_pipe", "gif",
+}
+
+// protocolTransports are the underlying protocols ffmpeg needs to open a URL of a certain scheme
+var protocolTransports = map[string][]string{
+	"http":  {"tcp"},
+	"https": {"tls", "tcp"},
+	"ftp":   {"tcp"},
+	"rtmp":  {"tcp"},
+	"rtmps": {"tls", "tcp"},
+	"rtsp":  {"rtp", "udp", "tcp"},
+}
+
+// privateNetworks are the networks a URL may not resolve to unless private networks are allowed
+var privateNetworks = mustParseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"64:ff9b:1::/48",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+// nat64Network and sixToFourNetwork are IPv6 networks routed to the IPv4 address embedded in the IPv6 address
+var (
+	nat64Network     = mustParseCIDRs("64:ff9b::/96")[0]
+	sixToFourNetwork = mustParseCIDRs("2002::/16")[0]
+)
+
+// URLPolicy restricts the URLs a Prober will probe, to safely probe URLs supplied by users. Without a policy, a URL
+// like concat:, file:/etc/passwd or an HTTP URL pointing to an internal host would happily be opened by ffprobe.
+//
+// Note that ffprobe resolves the host again by itself, so a malicious DNS server could still point it elsewhere.
+// ffprobe also follows HTTP redirects without consulting the policy, so a URL on an allowed host can redirect it to
+// an internal address. CheckRedirects rejects URLs that redirect to a host the policy does not allow, but ffprobe
+// requests the URL again by itself and a malicious server may redirect that request differently. Only probe URLs on
+// trusted hosts using AllowedHosts when that matters.
+type URLPolicy struct {
+	// AllowedSchemes are the allowed URL schemes. A URL without scheme uses the file scheme.
+	// When empty, DefaultAllowedSchemes is used.
+	AllowedSchemes []string
+	// AllowedHosts are the only hosts allowed when not empty. An entry starting with "*." matches any subdomain.
+	AllowedHosts []string
+	// DeniedHosts are hosts that are never allowed. An entry starting with "*." matches any subdomain.
+	DeniedHosts []string
+	// AllowPrivateNetworks allows hosts that resolve to loopback, private or link-local addresses
+	AllowPrivateNetworks bool
+	// AllowNestedResources allows demuxers that open other resources, like hls and concat. When false, only the
+	// demuxers in AllowedFormats can be used.
+	AllowNestedResources bool
+	// AllowedFormats are the demuxers that can be used when nested resources are not allowed.
+	// When empty, DefaultSafeFormats is used.
+	AllowedFormats []string
+	// Resolver is used to resolve hosts, net.DefaultResolver is used when nil
+	Resolver *net.Resolver
+	// CheckRedirects requests HTTP URLs before probing them and rejects them when they redirect to a URL the policy
+	// does not allow. ProbeURL sends the HTTPOptions of the Prober with the request, Validate sends none.
+	CheckRedirects bool
+}
+
+// maxRedirects is the maximum number of redirects followed when checking redirects, like ffmpeg
+const maxRedirects = 8
+
+// Validate checks the URL against the policy, returning an error wrapping ErrURLNotAllowed when it is rejected
+func (p *URLPolicy) Validate(ctx context.Context, fileURL string) error {
+	return p.validateURL(ctx, fileURL, nil)
+}
+
+// validateURL checks the URL against the policy, checking redirects using the HTTP options ffprobe will use
+func (p *URLPolicy) validateURL(ctx context.Context, fileURL string, httpOpts *HTTPOptions) error {
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrURLNotAllowed, err)
+	}
+	err = p.validate(ctx, u)
+	if err != nil {
+		return err
+	}
+	if p.CheckRedirects && isHTTPURL(fileURL) {
+		return p.checkRedirects(ctx, u, httpOpts)
+	}
+	return nil
+}
+
+// checkRedirects requests the URL like ffprobe would and validates every URL it redirects to
+func (p *URLPolicy) checkRedirects(ctx context.Context, u *url.URL, httpOpts *HTTPOptions) error {
+	if httpOpts == nil {
+		httpOpts = &HTTPOptions{}
+	}
+	client, err := httpOpts.client(u)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrURLNotAllowed, err)
+	}
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return fmt.Errorf("%w: too many redirects", ErrURLNotAllowed)
+		}
+		return p.validate(req.Context(), req.URL)
+	}
+
+	req, err := httpOpts.newRequest(ctx, u.String())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrURLNotAllowed, err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrURLNotAllowed) {
+			return err
+		}
+		return fmt.Errorf("%w: error checking redirects: %v", ErrURLNotAllowed, err)
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+// validate checks a single URL against the policy
+func (p *URLPolicy) validate(ctx context.Context, u *url.URL) error {
+	scheme := urlScheme(u)
+	if !containsFold(p.allowedSchemes(), scheme) {
+		return fmt.Errorf("%w: scheme %q is not allowed", ErrURLNotAllowed, scheme)
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		if scheme == "file" {
+			return nil
+		}
+		return fmt.Errorf("%w: no host", ErrURLNotAllowed)
+	}
+	if matchesHost(p.DeniedHosts, host) {
+		return fmt.Errorf("%w: host %q is denied", ErrURLNotAllowed, host)
+	}
+	if len(p.AllowedHosts) > 0 && !matchesHost(p.AllowedHosts, host) {
+		return fmt.Errorf("%w: host %q is not allowed", ErrURLNotAllowed, host)
+	}
+	if p.AllowPrivateNetworks {
+		return nil
+	}
+
+	ips, err := p.resolve(ctx, host)
+	if err != nil {
+		return fmt.Errorf("%w: error resolving host %q: %v", ErrURLNotAllowed, host, err)
+	}
+	for _, ip := range ips {
+		if isPrivateIP(ip) {
+			return fmt.Errorf("%w: host %q resolves to private address %s", ErrURLNotAllowed, host, ip)
+		}
+	}
+	return nil
+}
+
+// urlArgs returns the ffprobe arguments enforcing the policy for the given URL
+func (p *URLPolicy) urlArgs(fileURL string) []string {
+	scheme := "file"
+	if u, err := url.Parse(fileURL); err == nil {
+		scheme = urlScheme(u)
+	}
+
+	protocols := append([]string{scheme}, protocolTransports[scheme]...)
+	return p.args(protocols)
+}
+
+// args returns the ffprobe arguments enforcing the policy using the given protocols
+func (p *URLPolicy) args(protocols []string) []string {
+	args := []string{"-protocol_whitelist", strings.Join(protocols, ",")}
+	if !p.AllowNestedResources {
+		formats := p.AllowedFormats
+		if len(formats) == 0 {
+			formats = DefaultSafeFormats
+		}
+		args = append(args, "-format_whitelist", strings.Join(formats, ","))
+	}
+	return args
+}
+
+func (p *URLPolicy) allowedSchemes() []string {
+	if len(p.AllowedSchemes) == 0 {
+		return DefaultAllowedSchemes
+	}
+	return p.AllowedSchemes
+}
+
+func (p *URLPolicy) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+
+	resolver := p.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	ips := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		ips = append(ips, addr.IP)
+	}
+	return ips, nil
+}
+
+// urlScheme returns the lowercase scheme of the URL, defaulting to file like ffmpeg does
+func urlScheme(u *url.URL) string {
+	if u.Scheme == "" {
+		return "file"
+	}
+	return strings.ToLower(u.Scheme)
+}
+
+func containsFold(list []string, val string) bool {
+	for _, entry := range list {
+		if strings.EqualFold(entry, val) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesHost(patterns []string, host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if strings.HasPrefix(pattern, "*.") {
+			if strings.HasSuffix(host, pattern[1:]) {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func isPrivateIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	} else if embedded := embeddedIPv4(ip); embedded != nil && isPrivateIP(embedded) {
+		return true
+	}
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// embeddedIPv4 returns the IPv4 address embedded in a NAT64 or 6to4 address, or nil for any other address
+func embeddedIPv4(ip net.IP) net.IP {
+	ip = ip.To16()
+	switch {
+	case ip == nil:
+		return nil
+	case nat64Network.Contains(ip):
+		return net.IPv4(ip[12], ip[13], ip[14], ip[15])
+	case sixToFourNetwork.Contains(ip):
+		return net.IPv4(ip[2], ip[3], ip[4], ip[5])
+	}
+	return nil
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
@@ -0,0 +1,149 @@
+package ffprobe
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_URLPolicy_Validate(t *testing.T) {
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	tests := []struct {
+		policy  URLPolicy
+		url     string
+		allowed bool
+	}{
+		{URLPolicy{}, "https://8.8.8.8/video.mp4", true},
+		{URLPolicy{}, "HTTP://8.8.8.8/video.mp4", true},
+		{URLPolicy{}, "/etc/passwd", false},
+		{URLPolicy{}, "file:/etc/passwd", false},
+		{URLPolicy{}, "concat:a.mp4|b.mp4", false},
+		{URLPolicy{}, "http://127.0.0.1/video.mp4", false},
+		{URLPolicy{}, "http://10.1.2.3:8080/video.mp4", false},
+		{URLPolicy{}, "http://169.254.169.254/latest/meta-data", false},
+		{URLPolicy{}, "http://[::1]/video.mp4", false},
+		{URLPolicy{}, "http://[::ffff:192.168.1.1]/video.mp4", false},
+		{URLPolicy{}, "http://[64:ff9b::a9fe:a9fe]/latest/meta-data", false},
+		{URLPolicy{}, "http://[64:ff9b::808:808]/video.mp4", true},
+		{URLPolicy{}, "http://[2002:7f00:1::]/video.mp4", false},
+		{URLPolicy{}, "http://[2002:808:808::]/video.mp4", true},
+		{URLPolicy{AllowPrivateNetworks: true}, "http://127.0.0.1/video.mp4", true},
+		{URLPolicy{AllowedSchemes: []string{"file"}}, "/tmp/video.mp4", true},
+		{URLPolicy{AllowedSchemes: []string{"file"}}, "https://8.8.8.8/video.mp4", false},
+		{URLPolicy{DeniedHosts: []string{"*.internal"}}, "http://media.internal/video.mp4", false},
+		{URLPolicy{AllowedHosts: []string{"*.example.com"}}, "http://evil.com/video.mp4", false},
+		{URLPolicy{AllowedHosts: []string{"8.8.8.8"}}, "http://8.8.8.8/video.mp4", true},
+	}
+
+	for _, tt := range tests {
+		err := tt.policy.Validate(ctx, tt.url)
+		if tt.allowed && err != nil {
+			t.Errorf("Expected %s to be allowed, got %v", tt.url, err)
+		}
+		if !tt.allowed && !errors.Is(err, ErrURLNotAllowed) {
+			t.Errorf("Expected %s to be rejected, got %v", tt.url, err)
+		}
+	}
+}
+
+func Test_URLPolicy_Args(t *testing.T) {
+	policy := &URLPolicy{}
+	args := policy.urlArgs("https://8.8.8.8/video.mp4")
+	if len(args) != 4 || args[0] != "-protocol_whitelist" || args[1] != "https,tls,tcp" || args[2] != "-format_whitelist" {
+		t.Errorf("Unexpected arguments: %v", args)
+	}
+
+	policy.AllowNestedResources = true
+	args = policy.urlArgs("http://8.8.8.8/playlist.m3u8")
+	if len(args) != 2 || args[1] != "http,tcp" {
+		t.Errorf("Unexpected arguments: %v", args)
+	}
+}
+
+func Test_ProbeURL_URLPolicy(t *testing.T) {
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	prober := &Prober{URLPolicy: &URLPolicy{}}
+	_, err := prober.ProbeURL(ctx, "file:/etc/passwd")
+	if !errors.Is(err, ErrURLNotAllowed) {
+		t.Errorf("Expected ErrURLNotAllowed, got %v", err)
+	}
+}
+
+func Test_URLPolicy_Redirects(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+	// The same server under a host name the policy denies
+	deniedTarget := strings.Replace(target.URL, "127.0.0.1", "localhost", 1)
+
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/denied":
+			http.Redirect(w, r, deniedTarget+"/video.mp4", http.StatusFound)
+		case "/allowed":
+			http.Redirect(w, r, target.URL+"/video.mp4", http.StatusFound)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		}
+	}))
+	defer redirector.Close()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	policy := &URLPolicy{AllowPrivateNetworks: true, DeniedHosts: []string{"localhost"}}
+	// Without checking redirects, only the URL itself is validated
+	if err := policy.Validate(ctx, redirector.URL+"/denied"); err != nil {
+		t.Errorf("Expected the redirecting URL itself to be allowed, got %v", err)
+	}
+
+	policy.CheckRedirects = true
+	if err := policy.Validate(ctx, redirector.URL+"/denied"); !errors.Is(err, ErrURLNotAllowed) {
+		t.Errorf("Expected a redirect to a denied host to be rejected, got %v", err)
+	}
+	if err := policy.Validate(ctx, redirector.URL+"/loop"); !errors.Is(err, ErrURLNotAllowed) {
+		t.Errorf("Expected a redirect loop to be rejected, got %v", err)
+	}
+	if err := policy.Validate(ctx, redirector.URL+"/allowed"); err != nil {
+		t.Errorf("Expected a redirect to an allowed host to be allowed, got %v", err)
+	}
+}
+
+func Test_ProbeURL_RedirectsWithHTTPOptions(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+	deniedTarget := strings.Replace(target.URL, "127.0.0.1", "localhost", 1)
+
+	// Only authenticated requests with the session cookie are redirected to the denied host
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		if r.Header.Get("Authorization") != "Bearer secret" || err != nil || cookie.Value != "abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.Redirect(w, r, deniedTarget+"/video.mp4", http.StatusFound)
+	}))
+	defer redirector.Close()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	prober := &Prober{
+		URLPolicy: &URLPolicy{AllowPrivateNetworks: true, DeniedHosts: []string{"localhost"}, CheckRedirects: true},
+		HTTPOptions: &HTTPOptions{
+			Headers: http.Header{"Authorization": {"Bearer secret"}},
+			Cookies: []*http.Cookie{{Name: "session", Value: "abc"}},
+		},
+	}
+	_, err := prober.ProbeURL(ctx, redirector.URL+"/video.mp4")
+	if !errors.Is(err, ErrURLNotAllowed) {
+		t.Errorf("Expected the authenticated redirect to a denied host to be rejected, got %v", err)
+	}
+}
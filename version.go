@@ -0,0 +1,35 @@
+package ffprobe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sync"
+)
+
+// versions caches the version of every ffprobe binary used, by path
+var versions sync.Map
+
+// ffprobeVersion returns the first line of the version output of the current ffprobe binary, like
+// "ffprobe version 4.4.2 Copyright (c) 2007-2021 the FFmpeg developers". The result is cached per binary.
+func ffprobeVersion(ctx context.Context) (string, error) {
+	path := binPath
+	if version, ok := versions.Load(path); ok {
+		return version.(string), nil
+	}
+
+	cmd := exec.CommandContext(ctx, path, "-version") //nolint:gosec
+	cmd.SysProcAttr = procAttributes()
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("error getting %s version: %w", path, err)
+	}
+
+	version := string(bytes.TrimSpace(out))
+	if idx := bytes.IndexByte(out, '\n'); idx >= 0 {
+		version = string(bytes.TrimSpace(out[:idx]))
+	}
+	versions.Store(path, version)
+	return version, nil
+}
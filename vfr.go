@@ -0,0 +1,183 @@
+package ffprobe
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotEnoughFrames is returned when a stream has too few frames to analyze
+var ErrNotEnoughFrames = errors.New("not enough frames to analyze")
+
+// VFROptions are the options for DetectVFR
+type VFROptions struct {
+	// ReadIntervals limits the part of the file sampled, in the syntax of the ffprobe -read_intervals option.
+	// For example "%+30" samples the first 30 seconds, and "60%+10,600%+10" samples 10 seconds at 1 and 10 minutes.
+	// The whole file is sampled when empty.
+	ReadIntervals string
+	// Tolerance is the maximum relative deviation of a frame duration from the median frame duration for the frame to
+	// still count as constant frame rate. It absorbs the rounding of timestamps to the time base of the container.
+	// Defaults to 0.1.
+	Tolerance float64
+	// MaxDeviatingRatio is the maximum fraction of frames whose duration may deviate by more than the tolerance for
+	// the stream to still count as constant frame rate. It absorbs the odd dropped or duplicated frame.
+	// Defaults to 0.01.
+	MaxDeviatingRatio float64
+}
+
+// VFRReport describes whether a video stream has a constant or variable frame rate
+type VFRReport struct {
+	StreamIndex int
+	// Frames is the number of frames sampled
+	Frames int
+	// VariableFrameRate is true when more than the maximum deviating ratio of the frame durations deviate from the
+	// median by more than the tolerance
+	VariableFrameRate bool
+	// DeviatingFrames is the number of frames whose duration deviates from the median by more than the tolerance
+	DeviatingFrames int
+	// FrameDurations is the distribution of the frame durations, mapping every duration to the number of frames
+	FrameDurations map[time.Duration]int
+	// MinFrameDuration, MaxFrameDuration and MedianFrameDuration describe the spread of the frame durations
+	MinFrameDuration    time.Duration
+	MaxFrameDuration    time.Duration
+	MedianFrameDuration time.Duration
+	// MeasuredFrameRate is the mean frame rate of the frames within the tolerance of the median frame duration
+	MeasuredFrameRate float64
+	// FrameRate is the measured frame rate, snapped to a standard frame rate like 23.976 or 25 when it is close to one
+	FrameRate float64
+}
+
+// DetectVFR samples the frame timestamps of the video stream with the given index and reports whether it has a
+// constant or variable frame rate. The packet timestamps are used, so no decoding is needed.
+// Options may be nil to sample the whole stream with the default tolerance.
+func DetectVFR(ctx context.Context, fileURL string, streamIndex int, opts *VFROptions) (*VFRReport, error) {
+	return defaultProber.DetectVFR(ctx, fileURL, streamIndex, opts)
+}
+
+// DetectVFR samples the frame timestamps of a video stream, using the configuration of the Prober.
+// See the package level DetectVFR function for more details.
+func (p *Prober) DetectVFR(ctx context.Context, fileURL string, streamIndex int, opts *VFROptions) (*VFRReport, error) {
+	tolerance := 0.1
+	maxDeviatingRatio := 0.01
+	intervals := 1
+	args := []string{
+		"-select_streams", strconv.Itoa(streamIndex),
+		"-show_entries", "packet=pts_time",
+	}
+	if opts != nil {
+		if opts.Tolerance > 0 {
+			tolerance = opts.Tolerance
+		}
+		if opts.MaxDeviatingRatio > 0 {
+			maxDeviatingRatio = opts.MaxDeviatingRatio
+		}
+		if opts.ReadIntervals != "" {
+			args = append(args, "-read_intervals", opts.ReadIntervals)
+			intervals = strings.Count(strings.Trim(opts.ReadIntervals, ","), ",") + 1
+		}
+	}
+
+	var timestamps []time.Duration
+	err := p.streamEntries(ctx, fileURL, args, func(e entries) error {
+		if e[sectionKey] != "packet" {
+			return nil
+		}
+		if pts, ok := e.duration("pts_time"); ok {
+			timestamps = append(timestamps, pts)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return analyzeFrameTimestamps(streamIndex, timestamps, intervals, tolerance, maxDeviatingRatio)
+}
+
+// analyzeFrameTimestamps computes the frame durations from the timestamps, which are in decoding order and sampled
+// from the given number of read intervals. The gaps between the intervals are not frame durations, they are
+// recognized as the largest durations deviating from the median.
+func analyzeFrameTimestamps(streamIndex int, timestamps []time.Duration, intervals int, tolerance, maxDeviatingRatio float64) (*VFRReport, error) {
+	sortedTimestamps := make([]time.Duration, len(timestamps))
+	copy(sortedTimestamps, timestamps)
+	sort.Slice(sortedTimestamps, func(i, j int) bool {
+		return sortedTimestamps[i] < sortedTimestamps[j]
+	})
+
+	var frames int
+	var durations []time.Duration
+	for i, ts := range sortedTimestamps {
+		// Duplicate timestamps are not frames of their own
+		if i == 0 || ts > sortedTimestamps[i-1] {
+			frames++
+		}
+		if i > 0 && ts > sortedTimestamps[i-1] {
+			durations = append(durations, ts-sortedTimestamps[i-1])
+		}
+	}
+
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	if len(sorted) > 0 {
+		median := sorted[len(sorted)/2]
+		for gaps := 0; gaps < intervals-1 && len(sorted) > 1; gaps++ {
+			if !deviates(sorted[len(sorted)-1], median, tolerance) {
+				// The intervals are adjacent
+				break
+			}
+			durations = removeDuration(durations, sorted[len(sorted)-1])
+			sorted = sorted[:len(sorted)-1]
+		}
+	}
+	if len(durations) == 0 {
+		return nil, ErrNotEnoughFrames
+	}
+
+	report := &VFRReport{
+		StreamIndex:         streamIndex,
+		Frames:              frames,
+		FrameDurations:      make(map[time.Duration]int),
+		MinFrameDuration:    sorted[0],
+		MaxFrameDuration:    sorted[len(sorted)-1],
+		MedianFrameDuration: sorted[len(sorted)/2],
+	}
+	for _, d := range durations {
+		report.FrameDurations[d]++
+	}
+
+	var sum time.Duration
+	var regular int
+	for _, d := range durations {
+		if deviates(d, report.MedianFrameDuration, tolerance) {
+			report.DeviatingFrames++
+			continue
+		}
+		sum += d
+		regular++
+	}
+	report.VariableFrameRate = float64(report.DeviatingFrames)/float64(len(durations)) > maxDeviatingRatio
+	report.MeasuredFrameRate = float64(regular) / sum.Seconds()
+	report.FrameRate = snapFrameRate(report.MeasuredFrameRate)
+	return report, nil
+}
+
+// deviates returns whether the frame duration deviates from the median by more than the tolerance
+func deviates(d, median time.Duration, tolerance float64) bool {
+	deviation := (float64(d) - float64(median)) / float64(median)
+	return deviation > tolerance || deviation < -tolerance
+}
+
+// removeDuration removes the last occurrence of the duration
+func removeDuration(durations []time.Duration, d time.Duration) []time.Duration {
+	for i := len(durations) - 1; i >= 0; i-- {
+		if durations[i] == d {
+			return append(durations[:i], durations[i+1:]...)
+		}
+	}
+	return durations
+}
@@ -0,0 +1,123 @@
+package ffprobe
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func Test_analyzeFrameTimestamps(t *testing.T) {
+	// 10 seconds of 29.97 fps in a container with a millisecond time base, in decoding order with B-frames
+	var cfr []time.Duration
+	for i := 0; i < 300; i++ {
+		frame := i
+		if i%3 == 1 {
+			frame = i + 1
+		} else if i%3 == 2 {
+			frame = i - 1
+		}
+		pts := math.Round(float64(frame)*1001/30) * float64(time.Millisecond)
+		cfr = append(cfr, time.Duration(pts))
+	}
+
+	report, err := analyzeFrameTimestamps(0, cfr, 1, 0.1, 0.01)
+	if err != nil {
+		t.Fatalf("Error analyzing timestamps: %v", err)
+	}
+	if report.VariableFrameRate || report.DeviatingFrames != 0 {
+		t.Errorf("Expected constant frame rate, got %+v", report)
+	}
+	if report.Frames != 300 {
+		t.Errorf("Expected 300 frames, got %d", report.Frames)
+	}
+	if report.FrameRate != 30000.0/1001 {
+		t.Errorf("Expected frame rate of 29.97, got %f (measured %f)", report.FrameRate, report.MeasuredFrameRate)
+	}
+	if len(report.FrameDurations) != 2 || report.FrameDurations[33*time.Millisecond]+
+		report.FrameDurations[34*time.Millisecond] != 299 {
+		t.Errorf("Expected frame durations of 33 and 34 ms, got %v", report.FrameDurations)
+	}
+
+	// A phone recording that drops to 20 fps in low light
+	var vfr []time.Duration
+	var pts time.Duration
+	for i := 0; i < 100; i++ {
+		vfr = append(vfr, pts)
+		if i >= 40 && i < 60 {
+			pts += 50 * time.Millisecond
+		} else {
+			pts += 33333 * time.Microsecond
+		}
+	}
+
+	report, err = analyzeFrameTimestamps(0, vfr, 1, 0.1, 0.01)
+	if err != nil {
+		t.Fatalf("Error analyzing timestamps: %v", err)
+	}
+	if !report.VariableFrameRate || report.DeviatingFrames != 20 {
+		t.Errorf("Expected variable frame rate with 20 deviating frames, got %+v", report)
+	}
+	if report.MinFrameDuration != 33333*time.Microsecond || report.MaxFrameDuration != 50*time.Millisecond {
+		t.Errorf("Expected frame durations between 33.333 and 50 ms, got %v and %v",
+			report.MinFrameDuration, report.MaxFrameDuration)
+	}
+	if report.FrameRate != 30 {
+		t.Errorf("Expected dominant frame rate of 30, got %f", report.FrameRate)
+	}
+
+	_, err = analyzeFrameTimestamps(0, vfr[:1], 1, 0.1, 0.01)
+	if err != ErrNotEnoughFrames {
+		t.Errorf("Expected ErrNotEnoughFrames, got %v", err)
+	}
+}
+
+func Test_analyzeFrameTimestamps_DroppedFrame(t *testing.T) {
+	// 10 seconds of 25 fps with a single dropped frame
+	var timestamps []time.Duration
+	for i := 0; i < 250; i++ {
+		if i != 100 {
+			timestamps = append(timestamps, time.Duration(i)*40*time.Millisecond)
+		}
+	}
+
+	report, err := analyzeFrameTimestamps(0, timestamps, 1, 0.1, 0.01)
+	if err != nil {
+		t.Fatalf("Error analyzing timestamps: %v", err)
+	}
+	if report.VariableFrameRate || report.DeviatingFrames != 1 || report.Frames != 249 {
+		t.Errorf("Expected constant frame rate with a single deviating frame, got %+v", report)
+	}
+}
+
+func Test_analyzeFrameTimestamps_Intervals(t *testing.T) {
+	// 10 seconds of 25 fps sampled at 1 and 10 minutes, like the read intervals "60%+10,600%+10"
+	var timestamps []time.Duration
+	for _, start := range []time.Duration{60 * time.Second, 600 * time.Second} {
+		for i := 0; i < 250; i++ {
+			timestamps = append(timestamps, start+time.Duration(i)*40*time.Millisecond)
+		}
+	}
+
+	report, err := analyzeFrameTimestamps(0, timestamps, 2, 0.1, 0.01)
+	if err != nil {
+		t.Fatalf("Error analyzing timestamps: %v", err)
+	}
+	if report.VariableFrameRate || report.DeviatingFrames != 0 || report.Frames != 500 {
+		t.Errorf("Expected 500 frames at a constant frame rate, got %+v", report)
+	}
+	if report.MaxFrameDuration != 40*time.Millisecond || report.FrameDurations[40*time.Millisecond] != 498 {
+		t.Errorf("Expected only frame durations of 40ms, got %v", report.FrameDurations)
+	}
+	if report.FrameRate != 25 {
+		t.Errorf("Expected frame rate of 25, got %f", report.FrameRate)
+	}
+
+	// Adjacent intervals have no gap between them
+	report, err = analyzeFrameTimestamps(0, timestamps[:250], 2, 0.1, 0.01)
+	if err != nil {
+		t.Fatalf("Error analyzing timestamps: %v", err)
+	}
+	if report.FrameDurations[40*time.Millisecond] != 249 {
+		t.Errorf("Expected all frame durations to be kept, got %v", report.FrameDurations)
+	}
+}
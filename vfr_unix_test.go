@@ -0,0 +1,35 @@
+//go:build !windows
+// +build !windows
+
+package ffprobe
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_DetectVFR(t *testing.T) {
+	defer useFakeFFProbe(t, `case "$*" in
+*"-select_streams 1 -show_entries packet=pts_time -read_intervals %+1"*) ;;
+*) echo "unexpected arguments: $*" >&2; exit 1 ;;
+esac
+cat <<'EOF'
+packet|pts_time=0.000000
+packet|pts_time=0.080000
+packet|pts_time=0.040000
+packet|pts_time=0.120000
+packet|pts_time=0.160000
+EOF`)()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancelFn()
+
+	report, err := DetectVFR(ctx, testPath, 1, &VFROptions{ReadIntervals: "%+1"})
+	if err != nil {
+		t.Fatalf("Error detecting VFR: %v", err)
+	}
+	if report.StreamIndex != 1 || report.Frames != 5 || report.VariableFrameRate || report.FrameRate != 25 {
+		t.Errorf("Expected 5 frames at a constant 25 fps, got %+v", report)
+	}
+}